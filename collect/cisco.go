@@ -0,0 +1,313 @@
+package collect
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// CISCO-PROCESS-MIB cpmCPUTotalTable
+	ciscoCPUTotalPhysicalIndex = ".1.3.6.1.4.1.9.9.109.1.1.1.1.2"
+	ciscoCPUTotal1minRev       = ".1.3.6.1.4.1.9.9.109.1.1.1.1.7"
+	ciscoCPUTotal5minRev       = ".1.3.6.1.4.1.9.9.109.1.1.1.1.8"
+	// CISCO-MEMORY-POOL-MIB ciscoMemoryPoolTable
+	ciscoMemoryPoolName = ".1.3.6.1.4.1.9.9.48.1.1.1.2"
+	ciscoMemoryPoolUsed = ".1.3.6.1.4.1.9.9.48.1.1.1.5"
+	ciscoMemoryPoolFree = ".1.3.6.1.4.1.9.9.48.1.1.1.6"
+	// CISCO-ENHANCED-MEMPOOL-MIB cempMemPoolTable
+	ciscoMemPoolName   = ".1.3.6.1.4.1.9.9.221.1.1.1.1.3"
+	ciscoMemPoolUsed   = ".1.3.6.1.4.1.9.9.221.1.1.1.1.7"
+	ciscoMemPoolFree   = ".1.3.6.1.4.1.9.9.221.1.1.1.1.8"
+	ciscoMemPoolHCUsed = ".1.3.6.1.4.1.9.9.221.1.1.1.1.18"
+	ciscoMemPoolHCFree = ".1.3.6.1.4.1.9.9.221.1.1.1.1.20"
+	// CISCO-ENVMON-MIB
+	ciscoEnvMonTemperatureDescr = ".1.3.6.1.4.1.9.9.13.1.3.1.2"
+	ciscoEnvMonTemperatureValue = ".1.3.6.1.4.1.9.9.13.1.3.1.3"
+	ciscoEnvMonTemperatureState = ".1.3.6.1.4.1.9.9.13.1.3.1.6"
+	ciscoEnvMonFanDescr         = ".1.3.6.1.4.1.9.9.13.1.4.1.2"
+	ciscoEnvMonFanState         = ".1.3.6.1.4.1.9.9.13.1.4.1.3"
+	ciscoEnvMonSupplyDescr      = ".1.3.6.1.4.1.9.9.13.1.5.1.2"
+	ciscoEnvMonSupplyState      = ".1.3.6.1.4.1.9.9.13.1.5.1.3"
+)
+
+// ciscoEnvMonStates are the names of the CiscoEnvMonState values, the sensor metrics publish the value itself
+var ciscoEnvMonStates = map[int64]string{
+	1: "normal",
+	2: "warning",
+	3: "critical",
+	4: "shutdown",
+	5: "notPresent",
+	6: "notFunctioning",
+}
+
+func init() {
+	target.RegisterExtension("Cisco", target.NoOptions(newCisco))
+}
+
+// cisco is the extension collecting the CPU, memory pool and environmental sensor metrics of Cisco IOS devices
+// which do not populate the HOST-RESOURCES-MIB
+type cisco struct {
+	cpus   map[string]*info.CPUUtilisation // name : CPUUtilisation
+	pools  map[string]*info.MemoryPool     // name : MemoryPool
+	health map[string]*info.Sensor         // name : Sensor
+}
+
+func newCisco() target.Extension {
+	return new(cisco)
+}
+
+// Collect polls the CPU, memory pool and environmental monitor tables, an error in one does not stop the others
+// being polled
+func (c *cisco) Collect(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	var errs []string
+	err = c.collectCPU(t, verbose)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("cpu: %v", err))
+	}
+	err = c.collectMemory(t, verbose)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("memory: %v", err))
+	}
+	err = c.collectEnvMon(t, verbose)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("environment: %v", err))
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// collectCPU collects the one and five minute busy percentage of each CPU from cpmCPUTotalTable.
+// CPUs are named by the entPhysicalName of the physical entity they belong to where the device reports it.
+func (c *cisco) collectCPU(t *target.Target, verbose bool) error {
+	phys, err := walkTableColumn(t, ciscoCPUTotalPhysicalIndex)
+	if err != nil {
+		return err
+	}
+	var oid []string
+	for idx, p := range phys {
+		oid = append(oid, fmt.Sprintf("%s.%s", ciscoCPUTotal1minRev, idx))
+		oid = append(oid, fmt.Sprintf("%s.%s", ciscoCPUTotal5minRev, idx))
+		if pi := gosnmp.ToBigInt(p); pi.Sign() > 0 {
+			oid = append(oid, fmt.Sprintf("%s.%s", entPhysicalName, pi))
+		}
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string) // entPhysicalIndex : entPhysicalName
+	for _, variable := range vars {
+		if strings.HasPrefix(variable.Name, entPhysicalName+".") {
+			names[strings.TrimPrefix(variable.Name, entPhysicalName+".")] = pduString(variable.Value)
+		}
+	}
+	cpus := make(map[string]*info.CPUUtilisation)
+	byIndex := make(map[string]*info.CPUUtilisation)
+	for idx, p := range phys {
+		name := names[gosnmp.ToBigInt(p).String()]
+		if name == "" || cpus[name] != nil {
+			name = "cpu" + idx
+		}
+		cpu := &info.CPUUtilisation{Name: name}
+		cpus[name] = cpu
+		byIndex[idx] = cpu
+	}
+	for _, variable := range vars {
+		o := strings.Split(variable.Name, ".")
+		idx := o[len(o)-1]
+		if variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		switch strings.TrimSuffix(variable.Name, "."+idx) {
+		case ciscoCPUTotal1minRev:
+			if verbose {
+				log.Printf("processing SNMP response for cpmCPUTotal1minRev.%s from %s\n", idx, t.Name)
+			}
+			byIndex[idx].OneMinute = gosnmp.ToBigInt(variable.Value).Int64()
+		case ciscoCPUTotal5minRev:
+			if verbose {
+				log.Printf("processing SNMP response for cpmCPUTotal5minRev.%s from %s\n", idx, t.Name)
+			}
+			byIndex[idx].FiveMinute = gosnmp.ToBigInt(variable.Value).Int64()
+		}
+	}
+	c.cpus = cpus
+	return nil
+}
+
+// collectMemory collects the used and free bytes of each memory pool from cempMemPoolTable, falling back to
+// the older ciscoMemoryPoolTable where the device does not support CISCO-ENHANCED-MEMPOOL-MIB.
+func (c *cisco) collectMemory(t *target.Target, verbose bool) error {
+	names, err := walkTableColumn(t, ciscoMemPoolName)
+	if err != nil {
+		return err
+	}
+	usedCols := []string{ciscoMemPoolHCUsed, ciscoMemPoolUsed}
+	freeCols := []string{ciscoMemPoolHCFree, ciscoMemPoolFree}
+	if len(names) == 0 {
+		if verbose {
+			log.Printf("cempMemPoolTable not available on %s, using ciscoMemoryPoolTable\n", t.Name)
+		}
+		names, err = walkTableColumn(t, ciscoMemoryPoolName)
+		if err != nil {
+			return err
+		}
+		usedCols = []string{ciscoMemoryPoolUsed}
+		freeCols = []string{ciscoMemoryPoolFree}
+	}
+	var oid []string
+	for idx := range names {
+		for _, col := range append(usedCols, freeCols...) {
+			oid = append(oid, fmt.Sprintf("%s.%s", col, idx))
+		}
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]*big.Int)
+	for _, variable := range vars {
+		if variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		values[variable.Name] = gosnmp.ToBigInt(variable.Value)
+	}
+	// value returns the first non zero of the columns for the pool, preferring the high capacity counters
+	value := func(cols []string, idx string) *big.Int {
+		for _, col := range cols {
+			if v, ok := values[fmt.Sprintf("%s.%s", col, idx)]; ok && v.Sign() > 0 {
+				return v
+			}
+		}
+		return big.NewInt(0)
+	}
+	pools := make(map[string]*info.MemoryPool)
+	for idx, n := range names {
+		name := pduString(n)
+		if name == "" || pools[name] != nil {
+			// cempMemPoolTable is indexed by entPhysicalIndex.cempMemPoolIndex so the same pool name appears for
+			// each module of a stack or chassis
+			name = fmt.Sprintf("%s-%s", name, strings.ReplaceAll(idx, ".", "-"))
+		}
+		if verbose {
+			log.Printf("processing memory pool %s from %s\n", name, t.Name)
+		}
+		pools[name] = &info.MemoryPool{
+			Name: name,
+			Used: value(usedCols, idx),
+			Free: value(freeCols, idx),
+		}
+	}
+	c.pools = pools
+	return nil
+}
+
+// collectEnvMon collects the temperatures and the temperature, fan and power supply states from CISCO-ENVMON-MIB
+func (c *cisco) collectEnvMon(t *target.Target, verbose bool) error {
+	health := make(map[string]*info.Sensor)
+	ts := time.Now().UTC()
+	for _, s := range []struct {
+		typ   string
+		descr string
+		value string
+		state string
+	}{
+		{"temperature", ciscoEnvMonTemperatureDescr, ciscoEnvMonTemperatureValue, ciscoEnvMonTemperatureState},
+		{"fan", ciscoEnvMonFanDescr, "", ciscoEnvMonFanState},
+		{"power", ciscoEnvMonSupplyDescr, "", ciscoEnvMonSupplyState},
+	} {
+		descrs, err := walkTableColumn(t, s.descr)
+		if err != nil {
+			return err
+		}
+		if len(descrs) == 0 {
+			continue
+		}
+		states, err := walkTableColumn(t, s.state)
+		if err != nil {
+			return err
+		}
+		values := make(map[string]interface{})
+		if s.value != "" {
+			values, err = walkTableColumn(t, s.value)
+			if err != nil {
+				return err
+			}
+		}
+		for idx, d := range descrs {
+			name := strings.TrimSpace(pduString(d))
+			if name == "" {
+				name = fmt.Sprintf("%s%s", s.typ, idx)
+			}
+			state := gosnmp.ToBigInt(states[idx]).Int64()
+			if state == 5 {
+				// notPresent
+				continue
+			}
+			if verbose {
+				log.Printf("processing cisco %s sensor %s (%s) from %s\n", s.typ, name, ciscoEnvMonStates[state], t.Name)
+			}
+			if v, ok := values[idx]; ok {
+				health[name] = &info.Sensor{
+					Name:      name,
+					Type:      s.typ,
+					Unit:      "Cel",
+					Value:     float64(gosnmp.ToBigInt(v).Int64()),
+					Timestamp: ts,
+				}
+			}
+			health[name+"-state"] = &info.Sensor{
+				Name:      name + "-state",
+				Type:      "state",
+				Unit:      "1",
+				Value:     float64(state),
+				Timestamp: ts,
+			}
+		}
+	}
+	c.health = health
+	return nil
+}
+
+// Metrics returns the CPU, memory pool and environmental sensor metrics
+func (c *cisco) Metrics(t *target.Target) (metrics []*info.Metric) {
+	for _, cpu := range c.cpus {
+		name := info.MetricName(cpu.Name)
+		metrics = append(metrics,
+			&info.Metric{Type: fmt.Sprintf("cpu/%s/1min", name), Description: fmt.Sprintf("%s 1 minute usage", cpu.Name), Unit: "%", Value: cpu.OneMinute},
+			&info.Metric{Type: fmt.Sprintf("cpu/%s/5min", name), Description: fmt.Sprintf("%s 5 minute usage", cpu.Name), Unit: "%", Value: cpu.FiveMinute},
+		)
+	}
+	for _, p := range c.pools {
+		name := info.MetricName(p.Name)
+		metrics = append(metrics,
+			&info.Metric{Type: fmt.Sprintf("memory/pools/%s/used", name), Description: fmt.Sprintf("memory pool %s used", p.Name), Unit: "By", Value: p.Used.Int64()},
+			&info.Metric{Type: fmt.Sprintf("memory/pools/%s/free", name), Description: fmt.Sprintf("memory pool %s free", p.Name), Unit: "By", Value: p.Free.Int64()},
+		)
+	}
+	for n, s := range c.health {
+		name := info.MetricName(n)
+		metrics = append(metrics, &info.Metric{
+			Type:        "health/" + name,
+			Description: fmt.Sprintf("%s %s", n, s.Type),
+			Unit:        s.Unit,
+			Value:       s.Value,
+		})
+	}
+	return
+}
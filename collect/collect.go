@@ -1,13 +1,13 @@
 package collect
 
 import (
-	"encoding/hex"
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"math/big"
+	"net"
 	"os"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,41 +19,71 @@ import (
 	"github.com/soniah/gosnmp"
 )
 
+// gosnmpTimeout is the start of the error message gosnmp returns when a request is not answered
+const gosnmpTimeout = "request timeout (after "
+
 const (
-	ifHCInOctets                         = ".1.3.6.1.2.1.31.1.1.1.6"
-	ifHCOutOctets                        = ".1.3.6.1.2.1.31.1.1.1.10"
-	ifSpeed                              = ".1.3.6.1.2.1.2.2.1.5"
-	ifDescr                              = ".1.3.6.1.2.1.2.2.1.2"
-	hrStorageDescr                       = ".1.3.6.1.2.1.25.2.3.1.3"
-	hrStorageSize                        = ".1.3.6.1.2.1.25.2.3.1.5"
-	hrStorageUsed                        = ".1.3.6.1.2.1.25.2.3.1.6"
-	hrStorageAllocationUnits             = ".1.3.6.1.2.1.25.2.3.1.4"
-	hrProcessorLoad                      = ".1.3.6.1.2.1.25.3.3.1.2"
-	mikrotikWirelessClientCount          = ".1.3.6.1.4.1.14988.1.1.1.3.1.6"
-	mikrotikWirelessOverallCCQ           = ".1.3.6.1.4.1.14988.1.1.1.3.1.10"
-	mikrotikWirelessClientSignalStrength = ".1.3.6.1.4.1.14988.1.1.1.2.1.3"
-	mikrotikWirelessClientSNR            = ".1.3.6.1.4.1.14988.1.1.1.2.1.12"
+	ifHCInOctets             = ".1.3.6.1.2.1.31.1.1.1.6"
+	ifHCOutOctets            = ".1.3.6.1.2.1.31.1.1.1.10"
+	ifSpeed                  = ".1.3.6.1.2.1.2.2.1.5"
+	ifDescr                  = ".1.3.6.1.2.1.2.2.1.2"
+	hrStorageType            = ".1.3.6.1.2.1.25.2.3.1.2"
+	hrStorageDescr           = ".1.3.6.1.2.1.25.2.3.1.3"
+	hrStorageSize            = ".1.3.6.1.2.1.25.2.3.1.5"
+	hrStorageUsed            = ".1.3.6.1.2.1.25.2.3.1.6"
+	hrStorageAllocationUnits = ".1.3.6.1.2.1.25.2.3.1.4"
+	hrProcessorLoad          = ".1.3.6.1.2.1.25.3.3.1.2"
 )
 
+// hrStorageTypes maps the hrStorageTypes OID values to names
+var hrStorageTypes = map[string]string{
+	".1.3.6.1.2.1.25.2.1.1":  "Other",
+	".1.3.6.1.2.1.25.2.1.2":  "RAM",
+	".1.3.6.1.2.1.25.2.1.3":  "VirtualMemory",
+	".1.3.6.1.2.1.25.2.1.4":  "FixedDisk",
+	".1.3.6.1.2.1.25.2.1.5":  "RemovableDisk",
+	".1.3.6.1.2.1.25.2.1.6":  "FloppyDisk",
+	".1.3.6.1.2.1.25.2.1.7":  "CompactDisc",
+	".1.3.6.1.2.1.25.2.1.8":  "RamDisk",
+	".1.3.6.1.2.1.25.2.1.9":  "FlashMemory",
+	".1.3.6.1.2.1.25.2.1.10": "NetworkDisk",
+}
+
 func Run(t *target.Target, client *monitoring.MetricClient, wg *sync.WaitGroup, verbose bool) {
 	defer wg.Done()
 	for {
-		err := CPU(t, verbose)
+		err := poll(t, "system", System, verbose)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "system metrics collection from %s error: %v\n", t.Name, err)
+		}
+		err = poll(t, "cpu", CPU, verbose)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "cpu metrics collection from %s error: %v\n", t.Name, err)
 		}
-		err = Storage(t, verbose)
+		err = poll(t, "load", Load, verbose)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "load metrics collection from %s error: %v\n", t.Name, err)
+		}
+		err = poll(t, "storage", Storage, verbose)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "storage metrics collection from %s error: %v\n", t.Name, err)
 		}
-		err = Inferface(t, verbose)
+		err = poll(t, "memory", Memory, verbose)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "memory metrics collection from %s error: %v\n", t.Name, err)
+		}
+		err = poll(t, "interface", Inferface, verbose)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "interface metrics collection from %s error: %v\n", t.Name, err)
 		}
-		if t.Wireless != nil {
-			err = Mikrotik(t, verbose)
+		err = poll(t, "protocols", Protocols, verbose)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "protocol metrics collection from %s error: %v\n", t.Name, err)
+		}
+		for _, name := range t.ExtensionNames() {
+			err = poll(t, name, t.Exts[name].Collect, verbose)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "wireless metrics collection from %s error: %v\n", t.Name, err)
+				fmt.Fprintf(os.Stderr, "%s extension metrics collection from %s error: %v\n", name, t.Name, err)
 			}
 		}
 		t.CollectTime = time.Now().UTC()
@@ -71,12 +101,19 @@ func CPU(t *target.Target, verbose bool) error {
 		return err
 	}
 	defer t.Client.Conn.Close()
-	err = t.Client.BulkWalk(hrProcessorLoad, walkHRProcLoad(t, verbose))
+	err = bulkWalk(t, hrProcessorLoad, walkHRProcLoad(t, verbose))
 	if err != nil {
 		if _, ok := err.(EOWalk); !ok {
 			return err
 		}
 	}
+	var sum int64
+	for _, v := range t.CPU {
+		sum += v
+	}
+	if len(t.CPU) > 0 {
+		t.CPUAverage = float64(sum) / float64(len(t.CPU))
+	}
 	return nil
 }
 
@@ -86,24 +123,36 @@ func Storage(t *target.Target, verbose bool) error {
 		return err
 	}
 	defer t.Client.Conn.Close()
-	err = t.Client.BulkWalk(hrStorageDescr, walkHRStorage(t, verbose))
+	types := make(map[string]string)
+	err = bulkWalk(t, hrStorageType, walkHRStorageType(types))
 	if err != nil {
 		if _, ok := err.(EOWalk); !ok {
 			return err
 		}
 	}
+	index := make(map[string]string)
+	err = bulkWalk(t, hrStorageDescr, walkHRStorage(t, index, types))
+	if err != nil {
+		if _, ok := err.(EOWalk); !ok {
+			return err
+		}
+	}
+	reindexStorage(t, index, types, verbose)
 	var oid []string
 	for _, stInfo := range t.Storage {
+		if stInfo == nil {
+			continue
+		}
 		oid = append(oid, fmt.Sprintf("%s.%s", hrStorageSize, stInfo.OIDTail))
 		oid = append(oid, fmt.Sprintf("%s.%s", hrStorageUsed, stInfo.OIDTail))
 		oid = append(oid, fmt.Sprintf("%s.%s", hrStorageAllocationUnits, stInfo.OIDTail))
 	}
-	res, err := t.Client.Get(oid)
+	vars, err := get(t, oid)
 	if err != nil {
 		return err
 	}
 	ts := time.Now().UTC()
-	for _, variable := range res.Variables {
+	for _, variable := range vars {
 		oid := strings.Split(variable.Name, ".")
 		oidHead := strings.TrimSuffix(variable.Name, fmt.Sprintf(".%s", oid[len(oid)-1]))
 		stDescr := t.StrgIndex[oid[len(oid)-1]]
@@ -136,25 +185,27 @@ func Inferface(t *target.Target, verbose bool) error {
 		return err
 	}
 	defer t.Client.Conn.Close()
-	err = t.Client.BulkWalk(ifDescr, walkIfDesc(t))
+	index := make(map[string]string)
+	err = bulkWalk(t, ifDescr, walkIfDesc(t, index))
 	if err != nil {
 		if _, ok := err.(EOWalk); !ok {
 			return err
 		}
 	}
+	reindexIfaces(t, index, verbose)
 	var oid []string
 	for ifoid := range t.IfaceIndex {
 		oid = append(oid, fmt.Sprintf("%s.%s", ifSpeed, ifoid))
 		oid = append(oid, fmt.Sprintf("%s.%s", ifHCInOctets, ifoid))
 		oid = append(oid, fmt.Sprintf("%s.%s", ifHCOutOctets, ifoid))
 	}
-	res, err := t.Client.Get(oid)
+	vars, err := get(t, oid)
 	if err != nil {
 		return err
 	}
 	ts := time.Now().UTC()
 	eight := big.NewInt(int64(8))
-	for _, variable := range res.Variables {
+	for _, variable := range vars {
 		oid := strings.Split(variable.Name, ".")
 		desc := t.IfaceIndex[oid[len(oid)-1]]
 		ifInfo := t.Ifaces[desc]
@@ -169,11 +220,17 @@ func Inferface(t *target.Target, verbose bool) error {
 			if verbose {
 				log.Printf("processing SNMP response for ifHCInOctets from %s for %s\n", t.Name, desc)
 			}
-			ifInfo.Delta = ts.Sub(ifInfo.Timestamp)
-			ifInfo.Timestamp = ts
 			v := new(big.Int)
 			v.Mul(gosnmp.ToBigInt(variable.Value), eight)
-			ifInfo.InBitsDelta.Sub(v, ifInfo.InBits)
+			if ifInfo.Timestamp.IsZero() || v.Cmp(ifInfo.InBits) < 0 {
+				// No baseline yet or the counter has been reset so there is no valid delta this time
+				ifInfo.Delta = 0
+				ifInfo.InBitsDelta.SetInt64(0)
+			} else {
+				ifInfo.Delta = ts.Sub(ifInfo.Timestamp)
+				ifInfo.InBitsDelta.Sub(v, ifInfo.InBits)
+			}
+			ifInfo.Timestamp = ts
 			ifInfo.InBits = v
 		case ifHCOutOctets:
 			if verbose {
@@ -181,7 +238,11 @@ func Inferface(t *target.Target, verbose bool) error {
 			}
 			v := new(big.Int)
 			v.Mul(gosnmp.ToBigInt(variable.Value), eight)
-			ifInfo.OutBitsDelta.Sub(v, ifInfo.OutBits)
+			if ifInfo.Delta == 0 || v.Cmp(ifInfo.OutBits) < 0 {
+				ifInfo.OutBitsDelta.SetInt64(0)
+			} else {
+				ifInfo.OutBitsDelta.Sub(v, ifInfo.OutBits)
+			}
 			ifInfo.OutBits = v
 		}
 	}
@@ -194,21 +255,171 @@ func (e EOWalk) Error() string {
 	return "end of walk"
 }
 
-func walkIfDesc(t *target.Target) gosnmp.WalkFunc {
+// walkColumn records the value of each row of the table column at root into column (OID index : value).
+// The OID index is everything after the column OID so may have several parts for tables with compound indexes.
+func walkColumn(root string, column map[string]interface{}) gosnmp.WalkFunc {
+	return func(dataUnit gosnmp.SnmpPDU) error {
+		if !strings.HasPrefix(dataUnit.Name, root+".") {
+			return EOWalk{}
+		}
+		column[strings.TrimPrefix(dataUnit.Name, root+".")] = dataUnit.Value
+		return nil
+	}
+}
+
+// walkTableColumn walks the table column returning its values keyed by the index of each row.
+// The connection to the target must already be open.
+func walkTableColumn(t *target.Target, column string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	err := bulkWalk(t, column, walkColumn(column, values))
+	if err != nil {
+		if _, ok := err.(EOWalk); !ok {
+			return values, err
+		}
+	}
+	return values, nil
+}
+
+// pduString returns the value of an SNMP variable as a string or an empty string if the value is not a string
+func pduString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case []byte:
+		return string(s)
+	}
+	return ""
+}
+
+// get performs SNMP gets for the OIDs provided splitting them across as many requests as needed to keep within the
+// client's maximum number of OIDs per request.
+func get(t *target.Target, oids []string) ([]gosnmp.SnmpPDU, error) {
+	var vars []gosnmp.SnmpPDU
+	max := t.Client.MaxOids
+	if max < 1 {
+		max = gosnmp.MaxOids
+	}
+	for len(oids) > 0 {
+		n := max
+		if len(oids) < n {
+			n = len(oids)
+		}
+		res, err := t.Client.Get(oids[:n])
+		if err != nil {
+			countTimeout(t, err)
+			return vars, err
+		}
+		if t.ActivePoll != nil {
+			t.ActivePoll.Varbinds += int64(len(res.Variables))
+		}
+		vars = append(vars, res.Variables...)
+		oids = oids[n:]
+	}
+	return vars, nil
+}
+
+// bulkWalk walks the OID tree from root counting the variables returned towards the active poll of the target
+func bulkWalk(t *target.Target, root string, walkFn gosnmp.WalkFunc) error {
+	err := t.Client.BulkWalk(root, func(dataUnit gosnmp.SnmpPDU) error {
+		if t.ActivePoll != nil {
+			t.ActivePoll.Varbinds++
+		}
+		return walkFn(dataUnit)
+	})
+	if err != nil {
+		countTimeout(t, err)
+	}
+	return err
+}
+
+// countTimeout counts the error towards the timeouts of the active poll of the target if it is a request timeout
+func countTimeout(t *target.Target, err error) {
+	if t.ActivePoll != nil && isTimeout(err) {
+		t.ActivePoll.Timeouts++
+	}
+}
+
+// isTimeout reports if the error from a gosnmp request is because the target did not answer.
+// gosnmp does not return a typed error when a request times out on every retry, it returns an error with the
+// message "request timeout (after N retries)" from GoSNMP.sendOneRequest so the start of that is matched.
+// Where the client has a context with a deadline context.DeadlineExceeded is returned instead.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	return strings.HasPrefix(err.Error(), gosnmpTimeout)
+}
+
+// poll runs the collector recording its success, duration and the SNMP variables and timeouts of its requests
+// under the name given
+func poll(t *target.Target, name string, collector func(*target.Target, bool) error, verbose bool) error {
+	p, ok := t.Polls[name]
+	if !ok {
+		p = new(info.Poll)
+		t.Polls[name] = p
+	}
+	p.Start()
+	t.ActivePoll = p
+	start := time.Now().UTC()
+	err := collector(t, verbose)
+	t.ActivePoll = nil
+	p.Finish(start, err)
+	if verbose {
+		log.Printf("%s collection from %s took %v returning %d variables\n", name, t.Name, p.Duration, p.Varbinds)
+	}
+	return err
+}
+
+// walkIfDesc records the current ifIndex of each interface of interest into index (OIDTail : Descr)
+func walkIfDesc(t *target.Target, index map[string]string) gosnmp.WalkFunc {
+	seen := make(map[string]bool)
 	return func(dataUnit gosnmp.SnmpPDU) error {
 		if !strings.HasPrefix(dataUnit.Name, ifDescr) {
 			return EOWalk{}
 		}
 		desc := dataUnit.Value.(string)
-		if ifInfo, ok := t.Ifaces[desc]; ok && ifInfo == nil {
+		if _, ok := t.Ifaces[desc]; ok && !seen[desc] {
+			// where descriptions are duplicated the lowest index is used
+			seen[desc] = true
 			oid := strings.Split(dataUnit.Name, ".")
-			t.Ifaces[desc] = info.NewIface(desc, oid[len(oid)-1])
-			t.IfaceIndex[oid[len(oid)-1]] = desc
+			index[oid[len(oid)-1]] = desc
 		}
 		return nil
 	}
 }
 
+// reindexIfaces reconciles the tracked interfaces with the ifIndex values found in the latest walk of ifDescr.
+// Interfaces that have moved to a new ifIndex, for example after a device reboot, have their counter baselines reset.
+func reindexIfaces(t *target.Target, index map[string]string, verbose bool) {
+	found := make(map[string]bool)
+	for oidTail, desc := range index {
+		found[desc] = true
+		ifInfo := t.Ifaces[desc]
+		if ifInfo == nil {
+			t.Ifaces[desc] = info.NewIface(desc, oidTail)
+			if verbose {
+				log.Printf("interface %s on %s added for tracking at index %s\n", desc, t.Name, oidTail)
+			}
+			continue
+		}
+		if ifInfo.OIDTail != oidTail {
+			log.Printf("interface %s on %s has moved from index %s to %s, resetting counters\n", desc, t.Name, ifInfo.OIDTail, oidTail)
+			t.Ifaces[desc] = info.NewIface(desc, oidTail)
+		}
+	}
+	for desc, ifInfo := range t.Ifaces {
+		if ifInfo != nil && !found[desc] {
+			log.Printf("interface %s on %s is no longer present at index %s\n", desc, t.Name, ifInfo.OIDTail)
+			t.Ifaces[desc] = nil
+		}
+	}
+	t.IfaceIndex = index
+}
+
 func walkHRProcLoad(t *target.Target, verbose bool) gosnmp.WalkFunc {
 	return func(dataUnit gosnmp.SnmpPDU) error {
 		if !strings.HasPrefix(dataUnit.Name, hrProcessorLoad) {
@@ -224,7 +435,22 @@ func walkHRProcLoad(t *target.Target, verbose bool) gosnmp.WalkFunc {
 	}
 }
 
-func walkHRStorage(t *target.Target, verbose bool) gosnmp.WalkFunc {
+func walkHRStorageType(types map[string]string) gosnmp.WalkFunc {
+	return func(dataUnit gosnmp.SnmpPDU) error {
+		if !strings.HasPrefix(dataUnit.Name, hrStorageType) {
+			return EOWalk{}
+		}
+		oid := strings.Split(dataUnit.Name, ".")
+		if v, ok := dataUnit.Value.(string); ok {
+			types[oid[len(oid)-1]] = hrStorageTypes[v]
+		}
+		return nil
+	}
+}
+
+// walkHRStorage records the current index of each storage of interest into index (OIDTail : Descr)
+func walkHRStorage(t *target.Target, index, types map[string]string) gosnmp.WalkFunc {
+	seen := make(map[string]bool)
 	return func(dataUnit gosnmp.SnmpPDU) error {
 		if !strings.HasPrefix(dataUnit.Name, hrStorageDescr) {
 			return EOWalk{}
@@ -233,112 +459,66 @@ func walkHRStorage(t *target.Target, verbose bool) gosnmp.WalkFunc {
 		if desc == "/" {
 			desc = "root"
 		}
-		if _, ok := t.Storage[desc]; ok || len(t.StorageFilter) == 0 {
-			oid := strings.Split(dataUnit.Name, ".")
-			oidTail := oid[len(oid)-1]
-			t.Storage[desc] = info.NewStorage(desc, oidTail)
-			t.StrgIndex[oidTail] = desc
-			if verbose {
-				log.Printf("storage %s on %s added for tracking\n", desc, t.Name)
-			}
+		oid := strings.Split(dataUnit.Name, ".")
+		oidTail := oid[len(oid)-1]
+		if _, ok := t.Storage[desc]; (ok || len(t.StorageFilter) == 0) && !seen[desc] && !storageExcluded(t, desc, types[oidTail]) {
+			seen[desc] = true
+			index[oidTail] = desc
 		}
 		return nil
 	}
 }
 
-func Mikrotik(t *target.Target, verbose bool) error {
-	err := t.Client.Connect()
-	if err != nil {
-		return err
-	}
-	defer t.Client.Conn.Close()
-
-	if t.Wireless == nil {
-		return errors.New("mikrotik extension not configured for target")
-	}
-	if _, ok := t.Ifaces[t.Extensions.Mikrotik.WirelessInterface]; !ok {
-		return errors.New("could not find wireless interface")
-	}
-	oidSuffix := t.Ifaces[t.Extensions.Mikrotik.WirelessInterface].OIDTail
-
-	oid := []string{
-		fmt.Sprintf("%s.%s", mikrotikWirelessClientCount, oidSuffix),
-		fmt.Sprintf("%s.%s", mikrotikWirelessOverallCCQ, oidSuffix),
-	}
-	for _, wcl := range t.Extensions.Mikrotik.WirelessClients {
-		macoid, err := macToOidTail(wcl.MAC)
-		if err != nil {
-			return err
+// storageExcluded reports if the storage is excluded by the target's storage type or description exclusions
+func storageExcluded(t *target.Target, desc, typ string) bool {
+	for _, excl := range t.StorageExcludeTypes {
+		if strings.EqualFold(excl, typ) {
+			return true
 		}
-		if _, ok := t.Wireless.ClientConnections[macoid]; !ok {
-			t.Wireless.ClientConnections[macoid] = &info.WirelessClient{
-				Name:           wcl.Name,
-				MAC:            strings.ToUpper(wcl.MAC),
-				SNR:            big.NewInt(0),
-				SignalStrength: big.NewInt(0),
-			}
-		}
-		oid = append(oid, fmt.Sprintf("%s.%s.%s", mikrotikWirelessClientSignalStrength, macoid, oidSuffix))
-		oid = append(oid, fmt.Sprintf("%s.%s.%s", mikrotikWirelessClientSNR, macoid, oidSuffix))
 	}
-	res, err := t.Client.Get(oid)
-	if err != nil {
-		return err
+	for _, re := range t.StrgExclude {
+		if re.MatchString(desc) {
+			return true
+		}
 	}
-	for _, variable := range res.Variables {
-		if strings.HasPrefix(variable.Name, mikrotikWirelessClientCount) {
+	return false
+}
+
+// reindexStorage reconciles the tracked storage with the indexes found in the latest walk of hrStorageDescr.
+// Storage that is no longer present is dropped, unless it was explicitly configured in the storage filter.
+func reindexStorage(t *target.Target, index, types map[string]string, verbose bool) {
+	found := make(map[string]bool)
+	for oidTail, desc := range index {
+		found[desc] = true
+		stInfo := t.Storage[desc]
+		if stInfo == nil {
+			stInfo = info.NewStorage(desc, oidTail)
+			t.Storage[desc] = stInfo
 			if verbose {
-				log.Printf("processing SNMP response for mikrotikWirelessClientCount from %s\n", t.Name)
+				log.Printf("storage %s on %s added for tracking\n", desc, t.Name)
 			}
-			t.Wireless.ClientCount = gosnmp.ToBigInt(variable.Value)
+		} else if stInfo.OIDTail != oidTail {
+			log.Printf("storage %s on %s has moved from index %s to %s\n", desc, t.Name, stInfo.OIDTail, oidTail)
+			stInfo = info.NewStorage(desc, oidTail)
+			t.Storage[desc] = stInfo
 		}
-		if strings.HasPrefix(variable.Name, mikrotikWirelessOverallCCQ) {
-			if verbose {
-				log.Printf("processing SNMP response for mikrotikWirelessOverallCCQ from %s\n", t.Name)
-			}
-			t.Wireless.CCQ = gosnmp.ToBigInt(variable.Value)
-		}
-		if strings.HasPrefix(variable.Name, mikrotikWirelessClientSignalStrength) {
-			macoid := macOid(variable.Name, mikrotikWirelessClientSignalStrength)
-			if wcl, ok := t.Wireless.ClientConnections[macoid]; ok {
-				if verbose {
-					log.Printf("processing SNMP response for mikrotikWirelessClientSignalStrength from %s for %s\n", t.Name, wcl.MAC)
-				}
-				wcl.SignalStrength = gosnmp.ToBigInt(variable.Value)
-			}
+		stInfo.Type = types[oidTail]
+	}
+	for desc, stInfo := range t.Storage {
+		if found[desc] {
+			continue
 		}
-		if strings.HasPrefix(variable.Name, mikrotikWirelessClientSNR) {
-			macoid := macOid(variable.Name, mikrotikWirelessClientSNR)
-			if wcl, ok := t.Wireless.ClientConnections[macoid]; ok {
-				if verbose {
-					log.Printf("processing SNMP response for mikrotikWirelessClientSNR from %s for %s\n", t.Name, wcl.MAC)
-				}
-				wcl.SNR = gosnmp.ToBigInt(variable.Value)
+		if len(t.StorageFilter) > 0 {
+			if stInfo != nil {
+				log.Printf("storage %s on %s is no longer present at index %s\n", desc, t.Name, stInfo.OIDTail)
 			}
+			t.Storage[desc] = nil
+			continue
 		}
-	}
-	return nil
-}
-
-// macToOidTail will convert from a MAC address string of colon separated hex values to dot separated decimals string
-func macToOidTail(mac string) (string, error) {
-	var oid []string
-	for _, h := range strings.Split(mac, ":") {
-		if len(h) != 2 {
-			return "", fmt.Errorf("invalid mac address at %s", h)
-		}
-		b, err := hex.DecodeString(h)
-		if err != nil {
-			return "", fmt.Errorf("invalid mac address at %s: %v", h, err)
+		if verbose {
+			log.Printf("storage %s on %s is no longer present and has been dropped\n", desc, t.Name)
 		}
-		// b must have just one element as h was checked to be 2 in length
-		oid = append(oid, strconv.Itoa(int(b[0])))
+		delete(t.Storage, desc)
 	}
-	return strings.Join(oid, "."), nil
-}
-
-func macOid(fullOid string, prefix string) string {
-	oidTail := strings.TrimPrefix(fullOid, prefix+".")
-	oidSplit := strings.SplitN(oidTail, ".", 7)
-	return strings.Join(oidSplit[:6], ".")
+	t.StrgIndex = index
 }
@@ -0,0 +1,228 @@
+package collect
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// ENTITY-MIB entPhysicalTable
+	entPhysicalName = ".1.3.6.1.2.1.47.1.1.1.1.7"
+	// ENTITY-SENSOR-MIB entPhySensorTable, indexed by entPhysicalIndex
+	entPhySensorEntry      = ".1.3.6.1.2.1.99.1.1.1"
+	entPhySensorType       = entPhySensorEntry + ".1"
+	entPhySensorScale      = entPhySensorEntry + ".2"
+	entPhySensorPrecision  = entPhySensorEntry + ".3"
+	entPhySensorValue      = entPhySensorEntry + ".4"
+	entPhySensorOperStatus = entPhySensorEntry + ".5"
+
+	entPhySensorOperStatusOK = 1
+)
+
+// entPhySensorTypes maps the EntitySensorDataType values to the sensor type and its unit
+var entPhySensorTypes = map[int64]struct {
+	typ  string
+	unit string
+}{
+	1:  {"other", "1"},
+	2:  {"unknown", "1"},
+	3:  {"voltage-ac", "V"},
+	4:  {"voltage-dc", "V"},
+	5:  {"current", "A"},
+	6:  {"power", "W"},
+	7:  {"frequency", "Hz"},
+	8:  {"temperature", "Cel"},
+	9:  {"humidity", "%"},
+	10: {"fan", "{rpm}"},
+	11: {"airflow", "m3/min"},
+	12: {"state", "1"},
+	13: {"state", "1"},
+	14: {"optical-power", "dBm"},
+}
+
+// entPhySensorScales maps the EntitySensorDataScale values to the power of ten they represent
+var entPhySensorScales = map[int64]int{
+	1:  -24,
+	2:  -21,
+	3:  -18,
+	4:  -15,
+	5:  -12,
+	6:  -9,
+	7:  -6,
+	8:  -3,
+	9:  0,
+	10: 3,
+	11: 6,
+	12: 9,
+	13: 12,
+	14: 18,
+	15: 15,
+	16: 21,
+	17: 24,
+}
+
+func init() {
+	target.RegisterExtension("EntitySensor", newEntitySensor)
+}
+
+// entitySensor is the extension collecting the hardware sensors any vendor exposes through ENTITY-SENSOR-MIB
+type entitySensor struct {
+	// Include are regular expressions of the sensor names to collect, all sensors are collected if none are given
+	Include []string
+	// Exclude are regular expressions of the sensor names not to collect
+	Exclude []string
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	sensors map[string]*info.Sensor // name : Sensor
+}
+
+func newEntitySensor(data json.RawMessage) (target.Extension, error) {
+	e := new(entitySensor)
+	err := json.Unmarshal(data, e)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range e.Include {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %s: %v", p, err)
+		}
+		e.include = append(e.include, re)
+	}
+	for _, p := range e.Exclude {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %s: %v", p, err)
+		}
+		e.exclude = append(e.exclude, re)
+	}
+	return e, nil
+}
+
+// selected reports if the sensor name is matched by the include patterns and not by the exclude patterns
+func (e *entitySensor) selected(name string) bool {
+	for _, re := range e.exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	if len(e.include) == 0 {
+		return true
+	}
+	for _, re := range e.include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Collect walks entPhySensorTable and names each sensor from the entPhysicalName of its entity.
+// Sensors that are not operational are not collected.
+func (e *entitySensor) Collect(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	values, err := walkTableColumn(t, entPhySensorValue)
+	if err != nil {
+		return err
+	}
+	var oid []string
+	for idx := range values {
+		for _, col := range []string{entPhySensorType, entPhySensorScale, entPhySensorPrecision, entPhySensorOperStatus, entPhysicalName} {
+			oid = append(oid, fmt.Sprintf("%s.%s", col, idx))
+		}
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]interface{})
+	for _, variable := range vars {
+		if variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		columns[variable.Name] = variable.Value
+	}
+	col := func(c, idx string) interface{} {
+		return columns[fmt.Sprintf("%s.%s", c, idx)]
+	}
+	// entity names are not required to be unique so the index is added to those that are repeated
+	names := make(map[string]string)
+	count := make(map[string]int)
+	for idx := range values {
+		name := pduString(col(entPhysicalName, idx))
+		if name == "" {
+			name = "sensor" + idx
+		}
+		names[idx] = name
+		count[name]++
+	}
+	sensors := make(map[string]*info.Sensor)
+	ts := time.Now().UTC()
+	for idx, v := range values {
+		name := names[idx]
+		if count[name] > 1 {
+			name = fmt.Sprintf("%s(%s)", name, idx)
+		}
+		if !e.selected(name) {
+			continue
+		}
+		if gosnmp.ToBigInt(col(entPhySensorOperStatus, idx)).Int64() != entPhySensorOperStatusOK {
+			if verbose {
+				log.Printf("sensor %s on %s is not operational\n", name, t.Name)
+			}
+			continue
+		}
+		typ, ok := entPhySensorTypes[gosnmp.ToBigInt(col(entPhySensorType, idx)).Int64()]
+		if !ok {
+			typ = entPhySensorTypes[1]
+		}
+		if verbose {
+			log.Printf("processing entity sensor %s from %s\n", name, t.Name)
+		}
+		sensors[name] = &info.Sensor{
+			Name:      name,
+			Type:      typ.typ,
+			Unit:      typ.unit,
+			Value:     entitySensorValue(v, col(entPhySensorScale, idx), col(entPhySensorPrecision, idx)),
+			Timestamp: ts,
+		}
+	}
+	e.sensors = sensors
+	return nil
+}
+
+// entitySensorValue applies the scale and precision of an ENTITY-SENSOR-MIB reading to its value
+func entitySensorValue(value, scale, precision interface{}) float64 {
+	exp := entPhySensorScales[gosnmp.ToBigInt(scale).Int64()]
+	exp -= int(gosnmp.ToBigInt(precision).Int64())
+	return float64(gosnmp.ToBigInt(value).Int64()) * math.Pow10(exp)
+}
+
+// Metrics returns a metric for each type of sensor with a time series per sensor labelled with its name and type
+func (e *entitySensor) Metrics(t *target.Target) (metrics []*info.Metric) {
+	for _, s := range e.sensors {
+		metrics = append(metrics, &info.Metric{
+			Type:        "sensors/" + s.Type,
+			Description: s.Type + " sensors",
+			Unit:        s.Unit,
+			Labels: map[string]string{
+				"name": s.Name,
+				"type": s.Type,
+			},
+			Value: s.Value,
+		})
+	}
+	return
+}
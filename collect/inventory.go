@@ -0,0 +1,335 @@
+package collect
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// IP-MIB ipNetToPhysicalTable, indexed by ifIndex.addressType.length.address
+	ipNetToPhysicalPhysAddress = ".1.3.6.1.2.1.4.35.1.4"
+	ipNetToPhysicalType        = ".1.3.6.1.2.1.4.35.1.6"
+	// RFC1213-MIB ipNetToMediaTable, indexed by ifIndex.address
+	ipNetToMediaPhysAddress = ".1.3.6.1.2.1.4.22.1.2"
+	ipNetToMediaType        = ".1.3.6.1.2.1.4.22.1.4"
+	// Q-BRIDGE-MIB dot1qTpFdbTable, indexed by dot1qFdbId.mac
+	dot1qTpFdbPort   = ".1.3.6.1.2.1.17.7.1.2.2.1.2"
+	dot1qTpFdbStatus = ".1.3.6.1.2.1.17.7.1.2.2.1.3"
+	// BRIDGE-MIB dot1dTpFdbTable, indexed by mac
+	dot1dTpFdbPort   = ".1.3.6.1.2.1.17.4.3.1.2"
+	dot1dTpFdbStatus = ".1.3.6.1.2.1.17.4.3.1.3"
+	// BRIDGE-MIB dot1dBasePortTable maps bridge ports to ifIndex
+	dot1dBasePortIfIndex = ".1.3.6.1.2.1.17.1.4.1.2"
+
+	// the ipNetToMediaType and ipNetToPhysicalType value of entries that are not valid
+	ipNetTypeInvalid = 2
+	// the dot1dTpFdbStatus and dot1qTpFdbStatus value of the target's own MAC addresses
+	fdbStatusSelf = 4
+)
+
+func init() {
+	target.RegisterExtension("Inventory", target.NoOptions(newInventory))
+}
+
+// inventory is the extension collecting the hosts seen in the target's ARP and bridge forwarding tables
+type inventory struct {
+	hosts *info.Inventory
+	ports map[string]bool // the ports MAC addresses have been learnt on
+}
+
+func newInventory() target.Extension {
+	return &inventory{
+		hosts: info.NewInventory(),
+		ports: make(map[string]bool),
+	}
+}
+
+// Collect collects the MAC addresses in the target's ARP and bridge forwarding tables into an inventory of
+// the IP addresses and switch port of each MAC. MAC addresses that appear or disappear are recorded as events,
+// which are only logged when verbose as a busy switch will have many each poll.
+func (i *inventory) Collect(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	hosts := make(map[string]*info.Host)
+	host := func(mac string) *info.Host {
+		h, ok := hosts[mac]
+		if !ok {
+			h = &info.Host{MAC: mac}
+			hosts[mac] = h
+		}
+		return h
+	}
+
+	arp, err := arpTable(t)
+	if err != nil {
+		return err
+	}
+	for ip, mac := range arp {
+		h := host(mac)
+		h.IPs = append(h.IPs, ip)
+	}
+
+	fdb, err := fdbTable(t)
+	if err != nil {
+		return err
+	}
+	if len(fdb) > 0 {
+		descrs, err := walkTableColumn(t, ifDescr)
+		if err != nil {
+			return err
+		}
+		ports, err := walkTableColumn(t, dot1dBasePortIfIndex)
+		if err != nil {
+			return err
+		}
+		for mac, e := range fdb {
+			h := host(mac)
+			h.VLAN = e.vlan
+			h.Port = pduString(descrs[gosnmp.ToBigInt(ports[e.port]).String()])
+			if h.Port == "" {
+				h.Port = "bridgeport" + e.port
+			}
+		}
+	}
+
+	ts := time.Now().UTC()
+	inv := i.hosts
+	inv.Events = nil
+	for mac, h := range hosts {
+		sort.Strings(h.IPs)
+		h.LastSeen = ts
+		if prev, ok := inv.Hosts[mac]; ok {
+			h.FirstSeen = prev.FirstSeen
+			if verbose && prev.Port != h.Port {
+				log.Printf("MAC %s on %s has moved from port %s to %s\n", mac, t.Name, prev.Port, h.Port)
+			}
+			continue
+		}
+		h.FirstSeen = ts
+		if !inv.Timestamp.IsZero() {
+			// MACs found on the first poll have not newly appeared
+			if verbose {
+				log.Printf("MAC %s %v has appeared on %s port %s\n", mac, h.IPs, t.Name, h.Port)
+			}
+			inv.Events = append(inv.Events, &info.InventoryEvent{MAC: mac, Port: h.Port, Joined: true, Time: ts})
+		}
+	}
+	for mac, h := range inv.Hosts {
+		if _, ok := hosts[mac]; !ok {
+			if verbose {
+				log.Printf("MAC %s %v has disappeared from %s port %s\n", mac, h.IPs, t.Name, h.Port)
+			}
+			inv.Events = append(inv.Events, &info.InventoryEvent{MAC: mac, Port: h.Port, Time: ts})
+		}
+	}
+	inv.Hosts = hosts
+	inv.Timestamp = ts
+	for port := range inv.PortMACs() {
+		i.ports[port] = true
+	}
+	return nil
+}
+
+// Metrics returns the number of MAC addresses in total and on each port, and the number that appeared and
+// disappeared since the last poll. Ports that have had MAC addresses continue to be published with a count of
+// zero once they have gone quiet.
+func (i *inventory) Metrics(t *target.Target) (metrics []*info.Metric) {
+	if i.hosts.Timestamp.IsZero() {
+		return
+	}
+	joined, left := i.hosts.EventCounts()
+	metrics = append(metrics,
+		&info.Metric{Type: "inventory/macs", Description: "MAC address count", Unit: "1", Value: int64(len(i.hosts.Hosts))},
+		&info.Metric{Type: "inventory/macs/appeared", Description: "MAC addresses appeared since the last poll", Unit: "1", Value: joined},
+		&info.Metric{Type: "inventory/macs/disappeared", Description: "MAC addresses disappeared since the last poll", Unit: "1", Value: left},
+	)
+	counts := i.hosts.PortMACs()
+	for port := range i.ports {
+		metrics = append(metrics, &info.Metric{
+			Type:        fmt.Sprintf("inventory/ports/%s/macs", info.MetricName(port)),
+			Description: fmt.Sprintf("%s MAC address count", port),
+			Unit:        "1",
+			Value:       counts[port],
+		})
+	}
+	return
+}
+
+// arpTable returns the MAC address of each IP address in ipNetToPhysicalTable, or ipNetToMediaTable where the
+// target does not support the newer table
+func arpTable(t *target.Target) (map[string]string, error) {
+	arp := make(map[string]string)
+	macs, err := walkTableColumn(t, ipNetToPhysicalPhysAddress)
+	if err != nil {
+		return arp, err
+	}
+	if len(macs) > 0 {
+		types, err := walkTableColumn(t, ipNetToPhysicalType)
+		if err != nil {
+			return arp, err
+		}
+		for idx, m := range macs {
+			if gosnmp.ToBigInt(types[idx]).Int64() == ipNetTypeInvalid {
+				continue
+			}
+			ip := ipNetToPhysicalIndex(idx)
+			mac := macString(m)
+			if ip != "" && mac != "" {
+				arp[ip] = mac
+			}
+		}
+		return arp, nil
+	}
+	macs, err = walkTableColumn(t, ipNetToMediaPhysAddress)
+	if err != nil {
+		return arp, err
+	}
+	types, err := walkTableColumn(t, ipNetToMediaType)
+	if err != nil {
+		return arp, err
+	}
+	for idx, m := range macs {
+		if gosnmp.ToBigInt(types[idx]).Int64() == ipNetTypeInvalid {
+			continue
+		}
+		ip := ipNetToMediaIndex(idx)
+		mac := macString(m)
+		if ip != "" && mac != "" {
+			arp[ip] = mac
+		}
+	}
+	return arp, nil
+}
+
+// ipNetToPhysicalIndex returns the IP address from the index of ipNetToPhysicalTable,
+// ifIndex.addressType.length.address, or an empty string if the index does not hold an IPv4 or IPv6 address
+func ipNetToPhysicalIndex(idx string) string {
+	parts := strings.Split(idx, ".")
+	if len(parts) < 4 || parts[2] != strconv.Itoa(len(parts)-3) {
+		return ""
+	}
+	return oidIP(parts[3:])
+}
+
+// ipNetToMediaIndex returns the IP address from the index of ipNetToMediaTable, ifIndex.address, or an empty
+// string if the index does not hold an IPv4 address
+func ipNetToMediaIndex(idx string) string {
+	parts := strings.Split(idx, ".")
+	if len(parts) != net.IPv4len+1 {
+		return ""
+	}
+	return oidIP(parts[1:])
+}
+
+// fdbEntry is where a MAC address was learnt by a bridge
+type fdbEntry struct {
+	port string // bridge port number
+	vlan string
+}
+
+// fdbTable returns the bridge port each MAC address was learnt on from dot1qTpFdbTable, or dot1dTpFdbTable
+// where the target does not support Q-BRIDGE-MIB. The target's own MAC addresses are not included.
+func fdbTable(t *target.Target) (map[string]fdbEntry, error) {
+	fdb := make(map[string]fdbEntry)
+	ports, err := walkTableColumn(t, dot1qTpFdbPort)
+	if err != nil {
+		return fdb, err
+	}
+	statusCol := dot1qTpFdbStatus
+	if len(ports) == 0 {
+		ports, err = walkTableColumn(t, dot1dTpFdbPort)
+		if err != nil {
+			return fdb, err
+		}
+		statusCol = dot1dTpFdbStatus
+	}
+	status, err := walkTableColumn(t, statusCol)
+	if err != nil {
+		return fdb, err
+	}
+	for idx, p := range ports {
+		mac, vlan, ok := fdbIndex(idx)
+		if !ok || gosnmp.ToBigInt(status[idx]).Int64() == fdbStatusSelf {
+			continue
+		}
+		port := gosnmp.ToBigInt(p).String()
+		if port == "0" {
+			continue
+		}
+		// a MAC learnt in several VLANs is given the lowest numbered so its port does not change from poll to
+		// poll with the order the table is read in
+		if prev, ok := fdb[mac]; ok && !vlanLess(vlan, prev.vlan) {
+			continue
+		}
+		fdb[mac] = fdbEntry{port: port, vlan: vlan}
+	}
+	return fdb, nil
+}
+
+// fdbIndex returns the MAC address and VLAN from the index of dot1qTpFdbTable, dot1qFdbId.mac, or of
+// dot1dTpFdbTable, mac, where there is no VLAN
+func fdbIndex(idx string) (mac, vlan string, ok bool) {
+	parts := strings.Split(idx, ".")
+	switch len(parts) {
+	case 7:
+		vlan = parts[0]
+		parts = parts[1:]
+	case 6:
+	default:
+		return "", "", false
+	}
+	for _, p := range parts {
+		if b, err := strconv.Atoi(p); err != nil || b < 0 || b > 255 {
+			return "", "", false
+		}
+	}
+	return oidTailToMAC(strings.Join(parts, ".")), vlan, true
+}
+
+// vlanLess reports whether VLAN a is numbered lower than VLAN b
+func vlanLess(a, b string) bool {
+	x, _ := strconv.Atoi(a)
+	y, _ := strconv.Atoi(b)
+	return x < y
+}
+
+// oidIP returns the IP address from the OID index parts of an address.
+// The parts may be prefixed with the length of the address as in the InetAddress index encoding.
+func oidIP(parts []string) string {
+	if len(parts) == net.IPv4len+1 || len(parts) == net.IPv6len+1 {
+		parts = parts[1:]
+	}
+	if len(parts) != net.IPv4len && len(parts) != net.IPv6len {
+		return ""
+	}
+	b := make([]byte, len(parts))
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 || v > 255 {
+			return ""
+		}
+		b[i] = byte(v)
+	}
+	return net.IP(b).String()
+}
+
+// macString formats a MAC address octet string as upper case colon separated hex
+func macString(v interface{}) string {
+	s := pduString(v)
+	if len(s) != 6 {
+		return ""
+	}
+	return strings.ToUpper(net.HardwareAddr(s).String())
+}
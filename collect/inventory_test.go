@@ -0,0 +1,116 @@
+package collect
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOIDIP(t *testing.T) {
+	var tests = []struct {
+		name  string
+		parts string
+		want  string
+	}{
+		{"IPv4", "192.168.1.10", "192.168.1.10"},
+		{"IPv4 with length", "4.192.168.1.10", "192.168.1.10"},
+		{"IPv6", "254.128.0.0.0.0.0.0.2.17.34.255.254.51.68.85", "fe80::211:22ff:fe33:4455"},
+		{"IPv6 with length", "16.254.128.0.0.0.0.0.0.2.17.34.255.254.51.68.85", "fe80::211:22ff:fe33:4455"},
+		{"too short", "192.168.1", ""},
+		{"wrong length", "1.2.3.4.5.6", ""},
+		{"out of range", "192.168.1.256", ""},
+		{"not a number", "192.168.1.x", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := oidIP(strings.Split(test.parts, ".")); got != test.want {
+				t.Errorf("oidIP(%s) = %q, want %q", test.parts, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIPNetToPhysicalIndex(t *testing.T) {
+	var tests = []struct {
+		name string
+		idx  string
+		want string
+	}{
+		{"IPv4", "3.1.4.10.0.0.1", "10.0.0.1"},
+		{"IPv6", "3.2.16.32.1.13.184.0.0.0.0.0.0.0.0.0.0.0.1", "2001:db8::1"},
+		{"IPv4 with zone", "3.3.8.10.0.0.1.0.0.0.3", ""},
+		{"length mismatch", "3.1.5.10.0.0.1", ""},
+		{"too short", "3.1.4", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ipNetToPhysicalIndex(test.idx); got != test.want {
+				t.Errorf("ipNetToPhysicalIndex(%s) = %q, want %q", test.idx, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIPNetToMediaIndex(t *testing.T) {
+	var tests = []struct {
+		name string
+		idx  string
+		want string
+	}{
+		{"IPv4", "3.10.0.0.1", "10.0.0.1"},
+		{"no ifIndex", "10.0.0.1", ""},
+		{"too long", "3.4.10.0.0.1", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ipNetToMediaIndex(test.idx); got != test.want {
+				t.Errorf("ipNetToMediaIndex(%s) = %q, want %q", test.idx, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFDBIndex(t *testing.T) {
+	var tests = []struct {
+		name     string
+		idx      string
+		wantMAC  string
+		wantVLAN string
+		wantOK   bool
+	}{
+		{"dot1q", "10.0.17.34.51.68.255", "00:11:22:33:44:FF", "10", true},
+		{"dot1d", "0.17.34.51.68.255", "00:11:22:33:44:FF", "", true},
+		{"too short", "17.34.51.68.255", "", "", false},
+		{"too long", "1.10.0.17.34.51.68.255", "", "", false},
+		{"out of range", "0.17.34.51.68.256", "", "", false},
+		{"not a number", "0.17.34.51.68.x", "", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mac, vlan, ok := fdbIndex(test.idx)
+			if mac != test.wantMAC || vlan != test.wantVLAN || ok != test.wantOK {
+				t.Errorf("fdbIndex(%s) = %q, %q, %v, want %q, %q, %v", test.idx, mac, vlan, ok,
+					test.wantMAC, test.wantVLAN, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestMACString(t *testing.T) {
+	var tests = []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"octet string", string([]byte{0x00, 0x11, 0x22, 0xaa, 0xbb, 0xcc}), "00:11:22:AA:BB:CC"},
+		{"bytes", []byte{0x00, 0x11, 0x22, 0xaa, 0xbb, 0xcc}, "00:11:22:AA:BB:CC"},
+		{"wrong length", "\x00\x11", ""},
+		{"not a string", 5, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := macString(test.v); got != test.want {
+				t.Errorf("macString(%v) = %q, want %q", test.v, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package collect
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// LLDP-MIB lldpLocPortTable, indexed by lldpLocPortNum
+	lldpLocPortID   = ".1.0.8802.1.1.2.1.3.7.1.3"
+	lldpLocPortDesc = ".1.0.8802.1.1.2.1.3.7.1.4"
+	// LLDP-MIB lldpRemTable, indexed by lldpRemTimeMark.lldpRemLocalPortNum.lldpRemIndex
+	lldpRemEntry = ".1.0.8802.1.1.2.1.4.1.1"
+	// LLDP-MIB lldpRemManAddrTable, indexed by the lldpRemTable index followed by the address subtype and address
+	lldpRemManAddrIfSubtype = ".1.0.8802.1.1.2.1.4.2.1.3"
+)
+
+// lldpRemTable column numbers
+const (
+	lldpRemChassisIDSubtype = "4"
+	lldpRemChassisID        = "5"
+	lldpRemPortIDSubtype    = "6"
+	lldpRemPortID           = "7"
+	lldpRemPortDesc         = "8"
+	lldpRemSysName          = "9"
+	// the chassis and port ID subtype for a MAC address
+	lldpChassisIDMacAddress = 4
+	lldpPortIDMacAddress    = 3
+)
+
+func init() {
+	target.RegisterExtension("LLDP", target.NoOptions(newLLDP))
+}
+
+// lldp is the extension collecting the neighbours of the target from LLDP-MIB
+type lldp struct {
+	neighbours *info.LLDP
+	ports      map[string]bool // the local ports neighbours have been seen on
+}
+
+func newLLDP() target.Extension {
+	return &lldp{
+		neighbours: new(info.LLDP),
+		ports:      make(map[string]bool),
+	}
+}
+
+// Collect replaces the neighbours with those currently in the LLDP-MIB remote systems data
+func (l *lldp) Collect(t *target.Target, verbose bool) error {
+	nbrs, err := LLDP(t, verbose)
+	if err != nil {
+		return err
+	}
+	l.neighbours = nbrs
+	for _, nbr := range nbrs.Neighbours {
+		l.ports[nbr.LocalPort] = true
+	}
+	return nil
+}
+
+// Metrics returns the number of neighbours in total and on each local port.
+// Ports that have had neighbours continue to be published with a count of zero once they have lost them all.
+func (l *lldp) Metrics(t *target.Target) (metrics []*info.Metric) {
+	if l.neighbours.Timestamp.IsZero() {
+		return
+	}
+	metrics = append(metrics, &info.Metric{
+		Type:        "lldp/neighbours",
+		Description: "LLDP neighbour count",
+		Unit:        "1",
+		Value:       int64(len(l.neighbours.Neighbours)),
+	})
+	counts := l.neighbours.PortNeighbours()
+	for port := range l.ports {
+		metrics = append(metrics, &info.Metric{
+			Type:        fmt.Sprintf("lldp/ports/%s/neighbours", info.MetricName(port)),
+			Description: fmt.Sprintf("%s LLDP neighbour count", port),
+			Unit:        "1",
+			Value:       counts[port],
+		})
+	}
+	return
+}
+
+// LLDP returns the neighbours seen on each local port of the target from the LLDP-MIB remote systems data
+func LLDP(t *target.Target, verbose bool) (*info.LLDP, error) {
+	err := t.Client.Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer t.Client.Conn.Close()
+	portIDs, err := walkTableColumn(t, lldpLocPortID)
+	if err != nil {
+		return nil, err
+	}
+	portDescs, err := walkTableColumn(t, lldpLocPortDesc)
+	if err != nil {
+		return nil, err
+	}
+	rem, err := walkTableColumn(t, lldpRemEntry)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := walkTableColumn(t, lldpRemManAddrIfSubtype)
+	if err != nil {
+		return nil, err
+	}
+	// management addresses keyed by the lldpRemTable index
+	manAddrs := make(map[string][]string)
+	for idx := range addrs {
+		// index is timemark.localport.remindex.subtype.length.address
+		parts := strings.Split(idx, ".")
+		if len(parts) < 5 {
+			continue
+		}
+		if addr := lldpManAddr(parts[3], parts[5:]); addr != "" {
+			row := strings.Join(parts[:3], ".")
+			manAddrs[row] = append(manAddrs[row], addr)
+		}
+	}
+	var nbrs []*info.Neighbour
+	for idx, v := range rem {
+		// index is column.timemark.localport.remindex
+		parts := strings.SplitN(idx, ".", 2)
+		if len(parts) != 2 || parts[0] != lldpRemSysName {
+			continue
+		}
+		row := parts[1]
+		rowParts := strings.Split(row, ".")
+		if len(rowParts) != 3 {
+			continue
+		}
+		localPortNum := rowParts[1]
+		localPort := pduString(portDescs[localPortNum])
+		if localPort == "" {
+			localPort = lldpString(pduString(portIDs[localPortNum]))
+		}
+		if localPort == "" {
+			localPort = "port" + localPortNum
+		}
+		nbr := &info.Neighbour{
+			LocalPort:           localPort,
+			ChassisID:           lldpID(rem[lldpRemChassisIDSubtype+"."+row], rem[lldpRemChassisID+"."+row], lldpChassisIDMacAddress),
+			PortID:              lldpID(rem[lldpRemPortIDSubtype+"."+row], rem[lldpRemPortID+"."+row], lldpPortIDMacAddress),
+			PortDescription:     pduString(rem[lldpRemPortDesc+"."+row]),
+			SystemName:          pduString(v),
+			ManagementAddresses: manAddrs[row],
+		}
+		sort.Strings(nbr.ManagementAddresses)
+		if verbose {
+			log.Printf("processing LLDP neighbour %s port %s on %s port %s\n", nbr.SystemName, nbr.PortID, t.Name, nbr.LocalPort)
+		}
+		nbrs = append(nbrs, nbr)
+	}
+	sort.Slice(nbrs, func(i, j int) bool {
+		if nbrs[i].LocalPort != nbrs[j].LocalPort {
+			return nbrs[i].LocalPort < nbrs[j].LocalPort
+		}
+		return nbrs[i].SystemName < nbrs[j].SystemName
+	})
+	return &info.LLDP{
+		Neighbours: nbrs,
+		Timestamp:  time.Now().UTC(),
+	}, nil
+}
+
+// lldpID formats a chassis or port ID as a MAC address where its subtype says it is one, otherwise as text
+func lldpID(subtype, id interface{}, macSubtype int64) string {
+	s := pduString(id)
+	if gosnmp.ToBigInt(subtype).Int64() == macSubtype && len(s) == 6 {
+		return strings.ToUpper(net.HardwareAddr(s).String())
+	}
+	return lldpString(s)
+}
+
+// lldpString returns the octet string as text if it is printable otherwise as colon separated hex
+func lldpString(s string) string {
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			var h []string
+			for _, b := range []byte(s) {
+				h = append(h, fmt.Sprintf("%02X", b))
+			}
+			return strings.Join(h, ":")
+		}
+	}
+	return s
+}
+
+// lldpManAddr returns the management address from the OID index parts of the address, IPv4 and IPv6 addresses only
+func lldpManAddr(subtype string, addr []string) string {
+	var b []byte
+	for _, p := range addr {
+		i, err := strconv.Atoi(p)
+		if err != nil {
+			return ""
+		}
+		b = append(b, byte(i))
+	}
+	switch {
+	case subtype == "1" && len(b) == net.IPv4len, subtype == "2" && len(b) == net.IPv6len:
+		return net.IP(b).String()
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package collect
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLLDPManAddr(t *testing.T) {
+	var tests = []struct {
+		name    string
+		subtype string
+		addr    string
+		want    string
+	}{
+		{"IPv4", "1", "10.0.0.1", "10.0.0.1"},
+		{"IPv6", "2", "32.1.13.184.0.0.0.0.0.0.0.0.0.0.0.1", "2001:db8::1"},
+		{"IPv4 subtype with IPv6 length", "1", "32.1.13.184.0.0.0.0.0.0.0.0.0.0.0.1", ""},
+		{"IPv6 subtype with IPv4 length", "2", "10.0.0.1", ""},
+		{"other subtype", "6", "0.17.34.51.68.85", ""},
+		{"not a number", "1", "10.0.0.x", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := lldpManAddr(test.subtype, strings.Split(test.addr, ".")); got != test.want {
+				t.Errorf("lldpManAddr(%s, %s) = %q, want %q", test.subtype, test.addr, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLLDPID(t *testing.T) {
+	mac := string([]byte{0x00, 0x11, 0x22, 0xaa, 0xbb, 0xcc})
+	var tests = []struct {
+		name       string
+		subtype    interface{}
+		id         interface{}
+		macSubtype int64
+		want       string
+	}{
+		{"chassis MAC", 4, mac, lldpChassisIDMacAddress, "00:11:22:AA:BB:CC"},
+		{"port MAC", 3, mac, lldpPortIDMacAddress, "00:11:22:AA:BB:CC"},
+		{"interface name", 5, "ether1", lldpPortIDMacAddress, "ether1"},
+		{"MAC subtype with wrong length", 4, "\x00\x11", lldpChassisIDMacAddress, "00:11"},
+		{"binary without MAC subtype", 7, "\x01\x02", lldpChassisIDMacAddress, "01:02"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := lldpID(test.subtype, test.id, test.macSubtype); got != test.want {
+				t.Errorf("lldpID() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
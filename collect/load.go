@@ -0,0 +1,83 @@
+package collect
+
+import (
+	"log"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// UCD-SNMP-MIB laLoadInt is the load average multiplied by 100
+	laLoadInt1  = ".1.3.6.1.4.1.2021.10.1.5.1"
+	laLoadInt5  = ".1.3.6.1.4.1.2021.10.1.5.2"
+	laLoadInt15 = ".1.3.6.1.4.1.2021.10.1.5.3"
+	// UCD-SNMP-MIB ssCpuRaw counters are in ticks
+	ssCpuRawUser   = ".1.3.6.1.4.1.2021.11.50.0"
+	ssCpuRawNice   = ".1.3.6.1.4.1.2021.11.51.0"
+	ssCpuRawSystem = ".1.3.6.1.4.1.2021.11.52.0"
+	ssCpuRawIdle   = ".1.3.6.1.4.1.2021.11.53.0"
+	ssCpuRawWait   = ".1.3.6.1.4.1.2021.11.54.0"
+)
+
+// ssCpuRaw maps the ssCpuRaw OIDs to the names of CPU time used in info.CPURaw
+var ssCpuRaw = map[string]string{
+	ssCpuRawUser:   "user",
+	ssCpuRawNice:   "nice",
+	ssCpuRawSystem: "system",
+	ssCpuRawIdle:   "idle",
+	ssCpuRawWait:   "wait",
+}
+
+// Load collects the system load averages and raw CPU counters from UCD-SNMP-MIB
+func Load(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	oid := []string{laLoadInt1, laLoadInt5, laLoadInt15}
+	for o := range ssCpuRaw {
+		oid = append(oid, o)
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	ts := time.Now().UTC()
+	var load, raw bool
+	for _, variable := range vars {
+		if variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		v := gosnmp.ToBigInt(variable.Value)
+		switch variable.Name {
+		case laLoadInt1:
+			if verbose {
+				log.Printf("processing SNMP response for laLoadInt from %s\n", t.Name)
+			}
+			t.Load.One = float64(v.Int64()) / 100
+			load = true
+		case laLoadInt5:
+			t.Load.Five = float64(v.Int64()) / 100
+		case laLoadInt15:
+			t.Load.Fifteen = float64(v.Int64()) / 100
+		default:
+			if name, ok := ssCpuRaw[variable.Name]; ok {
+				if verbose {
+					log.Printf("processing SNMP response for ssCpuRaw %s from %s\n", name, t.Name)
+				}
+				t.CPURaw.Update(name, v)
+				raw = true
+			}
+		}
+	}
+	if load {
+		t.Load.Timestamp = ts
+	}
+	if raw {
+		t.CPURaw.Timestamp = ts
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package collect
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// UCD-SNMP-MIB memory scalars, values are in kB
+	memTotalSwap = ".1.3.6.1.4.1.2021.4.3.0"
+	memAvailSwap = ".1.3.6.1.4.1.2021.4.4.0"
+	memTotalReal = ".1.3.6.1.4.1.2021.4.5.0"
+	memAvailReal = ".1.3.6.1.4.1.2021.4.6.0"
+	memBuffer    = ".1.3.6.1.4.1.2021.4.14.0"
+	memCached    = ".1.3.6.1.4.1.2021.4.15.0"
+)
+
+// Memory collects the memory and swap usage of the target from UCD-SNMP-MIB, falling back to the hrStorage RAM and
+// virtual memory entries where the target does not support UCD-SNMP-MIB.
+func Memory(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	vars, err := get(t, []string{memTotalSwap, memAvailSwap, memTotalReal, memAvailReal, memBuffer, memCached})
+	if err != nil {
+		return err
+	}
+	kB := big.NewInt(1024)
+	var ucd bool
+	for _, variable := range vars {
+		if variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		v := new(big.Int)
+		v.Mul(gosnmp.ToBigInt(variable.Value), kB)
+		switch variable.Name {
+		case memTotalReal:
+			if verbose {
+				log.Printf("processing SNMP response for memTotalReal from %s\n", t.Name)
+			}
+			ucd = v.Sign() > 0
+			t.Memory.Total = v
+		case memAvailReal:
+			t.Memory.Available = v
+		case memBuffer:
+			t.Memory.Buffers = v
+		case memCached:
+			t.Memory.Cached = v
+		case memTotalSwap:
+			t.Memory.SwapTotal = v
+		case memAvailSwap:
+			t.Memory.SwapAvailable = v
+		}
+	}
+	if !ucd {
+		if verbose {
+			log.Printf("UCD-SNMP-MIB memory not available on %s, using hrStorage\n", t.Name)
+		}
+		return hrStorageMemory(t, verbose)
+	}
+	t.Memory.Timestamp = time.Now().UTC()
+	return nil
+}
+
+// hrStorageMemory populates the target's memory from the hrStorage RAM and swap entries.
+// The connection to the target must already be open.
+func hrStorageMemory(t *target.Target, verbose bool) error {
+	types := make(map[string]string)
+	err := bulkWalk(t, hrStorageType, walkHRStorageType(types))
+	if err != nil {
+		if _, ok := err.(EOWalk); !ok {
+			return err
+		}
+	}
+	descrs := make(map[string]interface{})
+	err = bulkWalk(t, hrStorageDescr, walkColumn(hrStorageDescr, descrs))
+	if err != nil {
+		if _, ok := err.(EOWalk); !ok {
+			return err
+		}
+	}
+	// OIDTail of the hrStorage entry for each part of memory
+	var ram, swap, buffers, cached string
+	for oidTail, typ := range types {
+		desc := strings.ToLower(pduString(descrs[oidTail]))
+		switch {
+		case typ == "RAM" && ram == "":
+			ram = oidTail
+		case typ == "VirtualMemory" && strings.Contains(desc, "swap"):
+			swap = oidTail
+		case typ == "Other" && desc == "memory buffers":
+			buffers = oidTail
+		case typ == "Other" && desc == "cached memory":
+			cached = oidTail
+		}
+	}
+	if ram == "" {
+		return fmt.Errorf("no RAM storage entry found")
+	}
+	var oid []string
+	for _, oidTail := range []string{ram, swap, buffers, cached} {
+		if oidTail == "" {
+			continue
+		}
+		oid = append(oid, fmt.Sprintf("%s.%s", hrStorageSize, oidTail))
+		oid = append(oid, fmt.Sprintf("%s.%s", hrStorageUsed, oidTail))
+		oid = append(oid, fmt.Sprintf("%s.%s", hrStorageAllocationUnits, oidTail))
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	size := make(map[string]*big.Int)
+	used := make(map[string]*big.Int)
+	units := make(map[string]*big.Int)
+	for _, variable := range vars {
+		oid := strings.Split(variable.Name, ".")
+		oidTail := oid[len(oid)-1]
+		oidHead := strings.TrimSuffix(variable.Name, "."+oidTail)
+		switch oidHead {
+		case hrStorageSize:
+			size[oidTail] = gosnmp.ToBigInt(variable.Value)
+		case hrStorageUsed:
+			used[oidTail] = gosnmp.ToBigInt(variable.Value)
+		case hrStorageAllocationUnits:
+			units[oidTail] = gosnmp.ToBigInt(variable.Value)
+		}
+	}
+	bytes := func(m map[string]*big.Int, oidTail string) *big.Int {
+		v := new(big.Int)
+		if m[oidTail] == nil || units[oidTail] == nil {
+			return v
+		}
+		return v.Mul(m[oidTail], units[oidTail])
+	}
+	if verbose {
+		log.Printf("processing hrStorage memory responses from %s\n", t.Name)
+	}
+	t.Memory.Total = bytes(size, ram)
+	t.Memory.Available = new(big.Int).Sub(t.Memory.Total, bytes(used, ram))
+	// hrStorageUsed of RAM includes buffers and cache where they are reported separately
+	t.Memory.Buffers = bytes(used, buffers)
+	t.Memory.Cached = bytes(used, cached)
+	t.Memory.SwapTotal = bytes(size, swap)
+	t.Memory.SwapAvailable = new(big.Int).Sub(t.Memory.SwapTotal, bytes(used, swap))
+	t.Memory.Timestamp = time.Now().UTC()
+	return nil
+}
@@ -0,0 +1,430 @@
+package collect
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	mikrotikWirelessApSSID        = ".1.3.6.1.4.1.14988.1.1.1.3.1.4"
+	mikrotikWirelessClientCount   = ".1.3.6.1.4.1.14988.1.1.1.3.1.6"
+	mikrotikWirelessApFreq        = ".1.3.6.1.4.1.14988.1.1.1.3.1.7"
+	mikrotikWirelessApBand        = ".1.3.6.1.4.1.14988.1.1.1.3.1.8"
+	mikrotikWirelessApNoiseFloor  = ".1.3.6.1.4.1.14988.1.1.1.3.1.9"
+	mikrotikWirelessOverallCCQ    = ".1.3.6.1.4.1.14988.1.1.1.3.1.10"
+	mikrotikWirelessRtab          = ".1.3.6.1.4.1.14988.1.1.1.2.1"
+	mikrotikWirelessCMRtab        = ".1.3.6.1.4.1.14988.1.1.1.5.1"
+	mikrotikWirelessCM            = ".1.3.6.1.4.1.14988.1.1.1.7.1"
+	mikrotikWirelessCMClientCount = ".1.3.6.1.4.1.14988.1.1.1.7.1.1"
+	mikrotikWirelessCMState       = ".1.3.6.1.4.1.14988.1.1.1.7.1.3"
+	mikrotikWirelessCMChannel     = ".1.3.6.1.4.1.14988.1.1.1.7.1.4"
+)
+
+// mikrotikRtabColumns are the column numbers of a wireless registration table
+type mikrotikRtabColumns struct {
+	signal  string
+	snr     string
+	txBytes string
+	rxBytes string
+	txRate  string
+	rxRate  string
+	upTime  string
+}
+
+var (
+	// mtxrWlRtab columns
+	mikrotikRtab = mikrotikRtabColumns{signal: "3", snr: "12", txBytes: "4", rxBytes: "5", txRate: "8", rxRate: "9", upTime: "11"}
+	// mtxrWlCMRtab columns, the CAPsMAN registration table has no SNR
+	mikrotikCMRtab = mikrotikRtabColumns{signal: "11", txBytes: "4", rxBytes: "5", txRate: "8", rxRate: "9", upTime: "3"}
+)
+
+// mikrotikWirelessApColumns are the mtxrWlApTable columns collected for each radio
+var mikrotikWirelessApColumns = []string{
+	mikrotikWirelessApSSID,
+	mikrotikWirelessClientCount,
+	mikrotikWirelessApFreq,
+	mikrotikWirelessApBand,
+	mikrotikWirelessApNoiseFloor,
+	mikrotikWirelessOverallCCQ,
+}
+
+func init() {
+	target.RegisterExtension("Mikrotik", newMikrotik)
+}
+
+// mikrotikConfig is the configuration of the Mikrotik extension for a target.
+// The client count, CCQ, frequency and noise floor of each radio are collected. Radio TX power is not as
+// MIKROTIK-MIB does not expose it. CAPsMAN radios only report their client count, state and channel, which
+// gives the frequency, so they have no CCQ or noise floor.
+type mikrotikConfig struct {
+	WirelessInterface string
+	// WirelessInterfaces are additional wireless interfaces to monitor, "*" monitors all wireless interfaces
+	WirelessInterfaces []string
+	// CAPsMAN monitors the remote CAP interfaces and their clients when the target is a CAPsMAN manager
+	CAPsMAN bool
+	// WirelessClients are the clients to monitor or, when DiscoverClients is set, the names to give discovered clients
+	WirelessClients []struct {
+		Name string `json:"Name"`
+		MAC  string `json:"MAC"`
+	} `json:"WirelessClientMACs"`
+	// DiscoverClients monitors all clients in the registration tables of the wireless interfaces
+	DiscoverClients bool
+	// Health collects the board temperature, voltage, fan and power supply sensors
+	Health bool
+	// Queues are regular expressions selecting the simple queues and queue tree entries to monitor by name
+	Queues []string
+}
+
+// mikrotik is the extension collecting the wireless, health and queue metrics of Mikrotik RouterOS devices
+type mikrotik struct {
+	mikrotikConfig
+	queuePatterns []*regexp.Regexp
+	wireless      *info.Wireless
+	health        map[string]*info.Sensor
+	queues        map[string]*info.Queue // kind/name : Queue
+}
+
+func newMikrotik(data json.RawMessage) (target.Extension, error) {
+	m := &mikrotik{
+		queues: make(map[string]*info.Queue),
+	}
+	err := json.Unmarshal(data, &m.mikrotikConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.radios()) > 0 || m.CAPsMAN {
+		m.wireless = info.NewWireless()
+	}
+	for _, p := range m.Queues {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid queue pattern %s: %v", p, err)
+		}
+		m.queuePatterns = append(m.queuePatterns, re)
+	}
+	return m, nil
+}
+
+// radios returns the names of all the wireless interfaces configured
+func (m *mikrotik) radios() []string {
+	var r []string
+	if m.WirelessInterface != "" {
+		r = append(r, m.WirelessInterface)
+	}
+	return append(r, m.WirelessInterfaces...)
+}
+
+// Collect polls the parts of the Mikrotik MIB configured, an error in one part does not stop the others being polled
+func (m *mikrotik) Collect(t *target.Target, verbose bool) error {
+	var errs []string
+	if m.wireless != nil {
+		err := m.collectWireless(t, verbose)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("wireless: %v", err))
+		}
+	}
+	if m.Health {
+		err := m.collectHealth(t, verbose)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("health: %v", err))
+		}
+	}
+	if len(m.queuePatterns) > 0 {
+		err := m.collectQueues(t, verbose)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("queue: %v", err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Metrics returns the wireless, health and queue metrics of the target
+func (m *mikrotik) Metrics(t *target.Target) []*info.Metric {
+	metrics := wirelessMetrics(m.wireless)
+	metrics = append(metrics, m.healthMetrics()...)
+	return append(metrics, m.queueMetrics()...)
+}
+
+// collectWireless collects the radios and wireless clients of the target
+func (m *mikrotik) collectWireless(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+
+	descrs := make(map[string]interface{})
+	err = bulkWalk(t, ifDescr, walkColumn(ifDescr, descrs))
+	if err != nil {
+		if _, ok := err.(EOWalk); !ok {
+			return err
+		}
+	}
+	// radios to monitor, ifIndex : interface name
+	radios := make(map[string]string)
+	wanted := make(map[string]bool)
+	for _, r := range m.radios() {
+		wanted[r] = true
+	}
+	if wanted["*"] {
+		counts := make(map[string]interface{})
+		err = bulkWalk(t, mikrotikWirelessClientCount, walkColumn(mikrotikWirelessClientCount, counts))
+		if err != nil {
+			if _, ok := err.(EOWalk); !ok {
+				return err
+			}
+		}
+		for idx := range counts {
+			radios[idx] = pduString(descrs[idx])
+		}
+	}
+	for idx, d := range descrs {
+		if wanted[pduString(d)] {
+			radios[idx] = pduString(d)
+		}
+	}
+	if len(radios) == 0 && !m.CAPsMAN {
+		return errors.New("could not find wireless interface")
+	}
+
+	m.wireless.Radios = make(map[string]*info.Radio)
+	var oid []string
+	for idx, name := range radios {
+		m.wireless.Radios[name] = info.NewRadio(name, idx)
+		for _, col := range mikrotikWirelessApColumns {
+			oid = append(oid, fmt.Sprintf("%s.%s", col, idx))
+		}
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	for _, variable := range vars {
+		oid := strings.Split(variable.Name, ".")
+		idx := oid[len(oid)-1]
+		radio := m.wireless.Radios[radios[idx]]
+		if radio == nil || variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		if verbose {
+			log.Printf("processing SNMP response for %s from %s for radio %s\n", variable.Name, t.Name, radio.Interface)
+		}
+		switch strings.TrimSuffix(variable.Name, "."+idx) {
+		case mikrotikWirelessApSSID:
+			radio.SSID = pduString(variable.Value)
+		case mikrotikWirelessClientCount:
+			radio.ClientCount = gosnmp.ToBigInt(variable.Value)
+		case mikrotikWirelessApFreq:
+			radio.Frequency = gosnmp.ToBigInt(variable.Value)
+		case mikrotikWirelessApBand:
+			radio.Band = pduString(variable.Value)
+		case mikrotikWirelessApNoiseFloor:
+			radio.NoiseFloor = gosnmp.ToBigInt(variable.Value)
+		case mikrotikWirelessOverallCCQ:
+			radio.CCQ = gosnmp.ToBigInt(variable.Value)
+		}
+	}
+
+	if m.CAPsMAN {
+		err = m.capsmanRadios(t, descrs, radios, verbose)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows := make(map[string]*mikrotikRtabRow)
+	err = walkRtab(t, mikrotikWirelessRtab, mikrotikRtab, radios, rows)
+	if err != nil {
+		return err
+	}
+	if m.CAPsMAN {
+		err = walkRtab(t, mikrotikWirelessCMRtab, mikrotikCMRtab, radios, rows)
+		if err != nil {
+			return err
+		}
+	}
+	m.updateWirelessClients(t, rows, radios, verbose)
+
+	wirelessTotals(m.wireless)
+	return nil
+}
+
+// capsmanRadios adds the remote CAP interfaces managed by the target to the radios being monitored.
+func (m *mikrotik) capsmanRadios(t *target.Target, descrs map[string]interface{}, radios map[string]string, verbose bool) error {
+	cm := make(map[string]interface{})
+	err := bulkWalk(t, mikrotikWirelessCM, walkColumn(mikrotikWirelessCM, cm))
+	if err != nil {
+		if _, ok := err.(EOWalk); !ok {
+			return err
+		}
+	}
+	countCol := strings.TrimPrefix(mikrotikWirelessCMClientCount, mikrotikWirelessCM+".")
+	for idx, v := range cm {
+		parts := strings.SplitN(idx, ".", 2)
+		if len(parts) != 2 || parts[0] != countCol {
+			continue
+		}
+		ifIndex := parts[1]
+		name := pduString(descrs[ifIndex])
+		if verbose {
+			log.Printf("processing CAPsMAN interface %s from %s\n", name, t.Name)
+		}
+		radio := info.NewRadio(name, ifIndex)
+		radio.CAPsMAN = true
+		radio.ClientCount = gosnmp.ToBigInt(v)
+		radio.State = pduString(cm[strings.TrimPrefix(mikrotikWirelessCMState, mikrotikWirelessCM+".")+"."+ifIndex])
+		radio.Channel = pduString(cm[strings.TrimPrefix(mikrotikWirelessCMChannel, mikrotikWirelessCM+".")+"."+ifIndex])
+		// the channel is of the form frequency/width-extension/band, e.g. 5180/20-Ce/ac
+		if f, err := strconv.ParseInt(strings.SplitN(radio.Channel, "/", 2)[0], 10, 64); err == nil {
+			radio.Frequency = big.NewInt(f)
+		}
+		radios[ifIndex] = name
+		m.wireless.Radios[name] = radio
+	}
+	return nil
+}
+
+// mikrotikRtabRow is the values of the registration table columns for a client, keyed by column number
+type mikrotikRtabRow struct {
+	ifIndex string
+	cols    mikrotikRtabColumns
+	values  map[string]interface{}
+}
+
+// walkRtab walks the registration table at root recording the rows for clients of the radios into rows keyed by
+// the client's MAC OID.
+func walkRtab(t *target.Target, root string, cols mikrotikRtabColumns, radios map[string]string, rows map[string]*mikrotikRtabRow) error {
+	rtab := make(map[string]interface{})
+	err := bulkWalk(t, root, walkColumn(root, rtab))
+	if err != nil {
+		if _, ok := err.(EOWalk); !ok {
+			return err
+		}
+	}
+	for idx, v := range rtab {
+		// index is column.mac(6 parts).ifIndex
+		parts := strings.Split(idx, ".")
+		if len(parts) != 8 {
+			continue
+		}
+		if _, ok := radios[parts[7]]; !ok {
+			continue
+		}
+		macoid := strings.Join(parts[1:7], ".")
+		row, ok := rows[macoid]
+		if !ok {
+			row = &mikrotikRtabRow{ifIndex: parts[7], cols: cols, values: make(map[string]interface{})}
+			rows[macoid] = row
+		}
+		if row.ifIndex != parts[7] {
+			// client registered on more than one radio, use the first found
+			continue
+		}
+		row.values[parts[0]] = v
+	}
+	return nil
+}
+
+// updateWirelessClients updates the clients being monitored from the registration table rows.
+// When discovering clients those newly associated are added and those that have left are removed, otherwise only
+// the configured clients are monitored.
+func (m *mikrotik) updateWirelessClients(t *target.Target, rows map[string]*mikrotikRtabRow, radios map[string]string, verbose bool) {
+	// names to give clients keyed by MAC OID
+	names := make(map[string]string)
+	for _, wcl := range m.WirelessClients {
+		macoid, err := macToOidTail(wcl.MAC)
+		if err != nil {
+			log.Printf("wireless client %s of %s ignored: %v\n", wcl.Name, t.Name, err)
+			continue
+		}
+		names[macoid] = wcl.Name
+		if _, ok := m.wireless.ClientConnections[macoid]; !ok && !m.DiscoverClients {
+			m.wireless.ClientConnections[macoid] = info.NewWirelessClient(wcl.Name, strings.ToUpper(wcl.MAC))
+		}
+	}
+	ts := time.Now().UTC()
+	for macoid, row := range rows {
+		wcl, ok := m.wireless.ClientConnections[macoid]
+		if !ok {
+			if !m.DiscoverClients {
+				continue
+			}
+			name, ok := names[macoid]
+			if !ok {
+				name = "unknown"
+			}
+			wcl = info.NewWirelessClient(name, oidTailToMAC(macoid))
+			m.wireless.ClientConnections[macoid] = wcl
+			if verbose {
+				log.Printf("wireless client %s(%s) has associated to %s on %s\n", wcl.Name, wcl.MAC, t.Name, radios[row.ifIndex])
+			}
+		}
+		if verbose {
+			log.Printf("processing registration table for wireless client %s(%s) from %s\n", wcl.Name, wcl.MAC, t.Name)
+		}
+		wcl.Interface = radios[row.ifIndex]
+		wcl.SignalStrength = gosnmp.ToBigInt(row.values[row.cols.signal])
+		wcl.SNR = gosnmp.ToBigInt(row.values[row.cols.snr])
+		wcl.TxRate = gosnmp.ToBigInt(row.values[row.cols.txRate])
+		wcl.RxRate = gosnmp.ToBigInt(row.values[row.cols.rxRate])
+		wcl.UpTime = time.Duration(gosnmp.ToBigInt(row.values[row.cols.upTime]).Int64()) * 10 * time.Millisecond
+		wcl.TxBytes.Update(gosnmp.ToBigInt(row.values[row.cols.txBytes]), ts)
+		wcl.RxBytes.Update(gosnmp.ToBigInt(row.values[row.cols.rxBytes]), ts)
+	}
+	for macoid, wcl := range m.wireless.ClientConnections {
+		if _, ok := rows[macoid]; ok {
+			continue
+		}
+		if m.DiscoverClients {
+			if verbose {
+				log.Printf("wireless client %s(%s) has left %s\n", wcl.Name, wcl.MAC, t.Name)
+			}
+			delete(m.wireless.ClientConnections, macoid)
+			continue
+		}
+		// configured client not currently associated
+		m.wireless.ClientConnections[macoid] = info.NewWirelessClient(wcl.Name, wcl.MAC)
+	}
+}
+
+// macToOidTail will convert from a MAC address string of colon separated hex values to dot separated decimals string
+func macToOidTail(mac string) (string, error) {
+	var oid []string
+	for _, h := range strings.Split(mac, ":") {
+		if len(h) != 2 {
+			return "", fmt.Errorf("invalid mac address at %s", h)
+		}
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			return "", fmt.Errorf("invalid mac address at %s: %v", h, err)
+		}
+		// b must have just one element as h was checked to be 2 in length
+		oid = append(oid, strconv.Itoa(int(b[0])))
+	}
+	return strings.Join(oid, "."), nil
+}
+
+// oidTailToMAC will convert from a dot separated decimals string to a MAC address string of colon separated hex values
+func oidTailToMAC(oidTail string) string {
+	var mac []string
+	for _, d := range strings.Split(oidTail, ".") {
+		i, _ := strconv.Atoi(d)
+		mac = append(mac, fmt.Sprintf("%02X", i))
+	}
+	return strings.Join(mac, ":")
+}
@@ -0,0 +1,161 @@
+package collect
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	mikrotikHealth = ".1.3.6.1.4.1.14988.1.1.3"
+	// mtxrGaugeTable used from RouterOS v7 (and late v6) in place of the mtxrHealth scalars
+	mikrotikGaugeName  = ".1.3.6.1.4.1.14988.1.1.3.100.1.2"
+	mikrotikGaugeValue = ".1.3.6.1.4.1.14988.1.1.3.100.1.3"
+	mikrotikGaugeUnit  = ".1.3.6.1.4.1.14988.1.1.3.100.1.4"
+)
+
+// healthSensor describes how to interpret a value from a vendor health MIB
+type healthSensor struct {
+	name  string
+	typ   string
+	unit  string
+	scale float64
+}
+
+// mikrotikHealthScalars are the mtxrHealth scalars keyed by their OID number under mtxrHealth
+var mikrotikHealthScalars = map[int]healthSensor{
+	1:  {"core-voltage", "voltage", "V", 0.1},
+	2:  {"3v3-voltage", "voltage", "V", 0.1},
+	3:  {"5v-voltage", "voltage", "V", 0.1},
+	4:  {"12v-voltage", "voltage", "V", 0.1},
+	5:  {"sensor-temperature", "temperature", "Cel", 0.1},
+	6:  {"cpu-temperature", "temperature", "Cel", 0.1},
+	7:  {"board-temperature", "temperature", "Cel", 0.1},
+	8:  {"voltage", "voltage", "V", 0.1},
+	10: {"temperature", "temperature", "Cel", 0.1},
+	11: {"processor-temperature", "temperature", "Cel", 0.1},
+	12: {"power-consumption", "power", "W", 0.1},
+	13: {"current", "current", "A", 0.001},
+	15: {"psu1-state", "state", "1", 1},
+	16: {"psu2-state", "state", "1", 1},
+	17: {"fan1-speed", "fan", "{rpm}", 1},
+	18: {"fan2-speed", "fan", "{rpm}", 1},
+}
+
+// mikrotikGaugeUnits maps the mtxrGaugeUnit values to sensor type, unit and scaling
+var mikrotikGaugeUnits = map[int64]healthSensor{
+	1: {"", "temperature", "Cel", 1},
+	2: {"", "fan", "{rpm}", 1},
+	3: {"", "voltage", "V", 0.1},
+	4: {"", "current", "A", 0.1},
+	5: {"", "power", "W", 0.1},
+	6: {"", "state", "1", 1},
+}
+
+// collectHealth collects the board health sensors from the Mikrotik health MIB.
+// The mtxrGaugeTable is used where the target has it otherwise the older mtxrHealth scalars are used.
+func (m *mikrotik) collectHealth(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	health := make(map[string]*info.Sensor)
+	names := make(map[string]interface{})
+	err = bulkWalk(t, mikrotikGaugeName, walkColumn(mikrotikGaugeName, names))
+	if err != nil {
+		if _, ok := err.(EOWalk); !ok {
+			return err
+		}
+	}
+	ts := time.Now().UTC()
+	if len(names) > 0 {
+		values := make(map[string]interface{})
+		err = bulkWalk(t, mikrotikGaugeValue, walkColumn(mikrotikGaugeValue, values))
+		if err != nil {
+			if _, ok := err.(EOWalk); !ok {
+				return err
+			}
+		}
+		units := make(map[string]interface{})
+		err = bulkWalk(t, mikrotikGaugeUnit, walkColumn(mikrotikGaugeUnit, units))
+		if err != nil {
+			if _, ok := err.(EOWalk); !ok {
+				return err
+			}
+		}
+		for idx, n := range names {
+			name := pduString(n)
+			u, ok := mikrotikGaugeUnits[gosnmp.ToBigInt(units[idx]).Int64()]
+			if name == "" || !ok {
+				continue
+			}
+			if verbose {
+				log.Printf("processing mikrotik gauge %s from %s\n", name, t.Name)
+			}
+			health[name] = &info.Sensor{
+				Name:      name,
+				Type:      u.typ,
+				Unit:      u.unit,
+				Value:     float64(gosnmp.ToBigInt(values[idx]).Int64()) * u.scale,
+				Timestamp: ts,
+			}
+		}
+		m.health = health
+		return nil
+	}
+
+	var oid []string
+	for n := range mikrotikHealthScalars {
+		oid = append(oid, fmt.Sprintf("%s.%d.0", mikrotikHealth, n))
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	for _, variable := range vars {
+		if variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		var n int
+		_, err := fmt.Sscanf(strings.TrimPrefix(variable.Name, mikrotikHealth+"."), "%d.0", &n)
+		if err != nil {
+			continue
+		}
+		s, ok := mikrotikHealthScalars[n]
+		if !ok {
+			continue
+		}
+		if verbose {
+			log.Printf("processing mikrotik health %s from %s\n", s.name, t.Name)
+		}
+		health[s.name] = &info.Sensor{
+			Name:      s.name,
+			Type:      s.typ,
+			Unit:      s.unit,
+			Value:     float64(gosnmp.ToBigInt(variable.Value).Int64()) * s.scale,
+			Timestamp: ts,
+		}
+	}
+	m.health = health
+	return nil
+}
+
+// healthMetrics returns a metric for each of the health sensors
+func (m *mikrotik) healthMetrics() (metrics []*info.Metric) {
+	for n, s := range m.health {
+		name := info.MetricName(n)
+		metrics = append(metrics, &info.Metric{
+			Type:        "health/" + name,
+			Description: fmt.Sprintf("%s %s", name, s.Type),
+			Unit:        s.Unit,
+			Value:       s.Value,
+		})
+	}
+	return
+}
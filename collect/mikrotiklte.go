@@ -0,0 +1,154 @@
+package collect
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	mikrotikLTEModemRSSI             = ".1.3.6.1.4.1.14988.1.1.16.1.1.2"
+	mikrotikLTEModemRSRQ             = ".1.3.6.1.4.1.14988.1.1.16.1.1.3"
+	mikrotikLTEModemRSRP             = ".1.3.6.1.4.1.14988.1.1.16.1.1.4"
+	mikrotikLTEModemCellID           = ".1.3.6.1.4.1.14988.1.1.16.1.1.5"
+	mikrotikLTEModemAccessTechnology = ".1.3.6.1.4.1.14988.1.1.16.1.1.6"
+	mikrotikLTEModemSINR             = ".1.3.6.1.4.1.14988.1.1.16.1.1.7"
+)
+
+// mikrotikLTEAccessTechnologies maps the mtxrLTEModemAccessTechnology values to names
+var mikrotikLTEAccessTechnologies = map[int64]string{
+	-1: "unknown",
+	0:  "gsmcompact",
+	1:  "gsm",
+	2:  "utran",
+	3:  "egprs",
+	4:  "hsdpa",
+	5:  "hsupa",
+	6:  "hsdpahsupa",
+	7:  "eutran",
+}
+
+func init() {
+	target.RegisterExtension("MikrotikLTE", newMikrotikLTE)
+}
+
+// mikrotikLTE is the extension collecting the signal quality of the LTE modems of Mikrotik RouterOS devices
+type mikrotikLTE struct {
+	// Interfaces are the LTE interfaces to monitor, all LTE interfaces are monitored if none are given
+	Interfaces []string
+	modems     map[string]*info.LTEModem // interface : LTEModem
+}
+
+func newMikrotikLTE(data json.RawMessage) (target.Extension, error) {
+	l := new(mikrotikLTE)
+	err := json.Unmarshal(data, l)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Collect collects the signal quality and serving cell of the target's LTE modems from mtxrLTEModemTable
+func (l *mikrotikLTE) Collect(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	rssi := make(map[string]interface{})
+	err = bulkWalk(t, mikrotikLTEModemRSSI, walkColumn(mikrotikLTEModemRSSI, rssi))
+	if err != nil {
+		if _, ok := err.(EOWalk); !ok {
+			return err
+		}
+	}
+	wanted := make(map[string]bool)
+	for _, iface := range l.Interfaces {
+		wanted[iface] = true
+	}
+	var descrOid []string
+	for ifIndex := range rssi {
+		descrOid = append(descrOid, fmt.Sprintf("%s.%s", ifDescr, ifIndex))
+	}
+	descrs, err := get(t, descrOid)
+	if err != nil {
+		return err
+	}
+	var oid []string
+	modems := make(map[string]*info.LTEModem)
+	index := make(map[string]string) // OIDTail : interface
+	for _, d := range descrs {
+		ifIndex := strings.TrimPrefix(d.Name, ifDescr+".")
+		iface := pduString(d.Value)
+		if len(wanted) > 0 && !wanted[iface] {
+			continue
+		}
+		modems[iface] = &info.LTEModem{Interface: iface, OIDTail: ifIndex}
+		index[ifIndex] = iface
+		for _, col := range []string{mikrotikLTEModemRSSI, mikrotikLTEModemRSRQ, mikrotikLTEModemRSRP,
+			mikrotikLTEModemCellID, mikrotikLTEModemAccessTechnology, mikrotikLTEModemSINR} {
+			oid = append(oid, fmt.Sprintf("%s.%s", col, ifIndex))
+		}
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	ts := time.Now().UTC()
+	for _, variable := range vars {
+		o := strings.Split(variable.Name, ".")
+		ifIndex := o[len(o)-1]
+		m := modems[index[ifIndex]]
+		if m == nil {
+			continue
+		}
+		if verbose {
+			log.Printf("processing SNMP response for %s from %s for LTE modem %s\n", variable.Name, t.Name, m.Interface)
+		}
+		m.Timestamp = ts
+		switch strings.TrimSuffix(variable.Name, "."+ifIndex) {
+		case mikrotikLTEModemRSSI:
+			m.RSSI = gosnmp.ToBigInt(variable.Value).Int64()
+		case mikrotikLTEModemRSRQ:
+			m.RSRQ = gosnmp.ToBigInt(variable.Value).Int64()
+		case mikrotikLTEModemRSRP:
+			m.RSRP = gosnmp.ToBigInt(variable.Value).Int64()
+		case mikrotikLTEModemSINR:
+			m.SINR = gosnmp.ToBigInt(variable.Value).Int64()
+		case mikrotikLTEModemCellID:
+			m.CellID = gosnmp.ToBigInt(variable.Value).String()
+		case mikrotikLTEModemAccessTechnology:
+			m.AccessTechnology = mikrotikLTEAccessTechnologies[gosnmp.ToBigInt(variable.Value).Int64()]
+		}
+	}
+	l.modems = modems
+	return nil
+}
+
+// Metrics returns the signal quality of each LTE modem labelled with its serving cell
+func (l *mikrotikLTE) Metrics(t *target.Target) (metrics []*info.Metric) {
+	for _, m := range l.modems {
+		if m.Timestamp.IsZero() {
+			continue
+		}
+		labels := map[string]string{
+			"cell_id":           m.CellID,
+			"access_technology": m.AccessTechnology,
+		}
+		prefix := fmt.Sprintf("lte/%s", info.MetricName(m.Interface))
+		descr := fmt.Sprintf("LTE %s", m.Interface)
+		metrics = append(metrics,
+			&info.Metric{Type: prefix + "/rssi", Description: descr + " RSSI", Unit: "dBm", Labels: labels, Value: m.RSSI},
+			&info.Metric{Type: prefix + "/rsrp", Description: descr + " RSRP", Unit: "dBm", Labels: labels, Value: m.RSRP},
+			&info.Metric{Type: prefix + "/rsrq", Description: descr + " RSRQ", Unit: "dB", Labels: labels, Value: m.RSRQ},
+			&info.Metric{Type: prefix + "/sinr", Description: descr + " SINR", Unit: "dB", Labels: labels, Value: m.SINR},
+		)
+	}
+	return
+}
@@ -0,0 +1,168 @@
+package collect
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	mikrotikQueueSimpleName       = ".1.3.6.1.4.1.14988.1.1.2.1.1.2"
+	mikrotikQueueSimpleBytesIn    = ".1.3.6.1.4.1.14988.1.1.2.1.1.8"
+	mikrotikQueueSimpleBytesOut   = ".1.3.6.1.4.1.14988.1.1.2.1.1.9"
+	mikrotikQueueSimplePacketsIn  = ".1.3.6.1.4.1.14988.1.1.2.1.1.10"
+	mikrotikQueueSimplePacketsOut = ".1.3.6.1.4.1.14988.1.1.2.1.1.11"
+	mikrotikQueueSimpleDroppedIn  = ".1.3.6.1.4.1.14988.1.1.2.1.1.14"
+	mikrotikQueueSimpleDroppedOut = ".1.3.6.1.4.1.14988.1.1.2.1.1.15"
+	mikrotikQueueTreeName         = ".1.3.6.1.4.1.14988.1.1.2.2.1.2"
+	mikrotikQueueTreePackets      = ".1.3.6.1.4.1.14988.1.1.2.2.1.6"
+	mikrotikQueueTreeHCBytes      = ".1.3.6.1.4.1.14988.1.1.2.2.1.7"
+	mikrotikQueueTreeDropped      = ".1.3.6.1.4.1.14988.1.1.2.2.1.8"
+)
+
+// mikrotikQueueCounters maps the counter columns of each kind of queue to the counter names used in info.Queue
+var mikrotikQueueCounters = map[string]map[string]string{
+	"simple": {
+		mikrotikQueueSimpleBytesIn:    "bytesin",
+		mikrotikQueueSimpleBytesOut:   "bytesout",
+		mikrotikQueueSimplePacketsIn:  "packetsin",
+		mikrotikQueueSimplePacketsOut: "packetsout",
+		mikrotikQueueSimpleDroppedIn:  "dropsin",
+		mikrotikQueueSimpleDroppedOut: "dropsout",
+	},
+	"tree": {
+		mikrotikQueueTreeHCBytes: "bytes",
+		mikrotikQueueTreePackets: "packets",
+		mikrotikQueueTreeDropped: "drops",
+	},
+}
+
+// collectQueues collects the counters of the simple queues and queue tree entries with names matching the
+// queue patterns configured for the target.
+func (m *mikrotik) collectQueues(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	// OIDTail : queue key for each kind of queue
+	index := map[string]map[string]string{
+		"simple": make(map[string]string),
+		"tree":   make(map[string]string),
+	}
+	for kind, nameOid := range map[string]string{"simple": mikrotikQueueSimpleName, "tree": mikrotikQueueTreeName} {
+		names := make(map[string]interface{})
+		err = bulkWalk(t, nameOid, walkColumn(nameOid, names))
+		if err != nil {
+			if _, ok := err.(EOWalk); !ok {
+				return err
+			}
+		}
+		seen := make(map[string]bool)
+		for oidTail, n := range names {
+			name := pduString(n)
+			if m.queueSelected(name) && !seen[name] {
+				// queue names are unique within each kind on RouterOS
+				seen[name] = true
+				index[kind][oidTail] = kind + "/" + name
+			}
+		}
+	}
+	m.reindexQueues(t, index, verbose)
+
+	var oid []string
+	for _, q := range m.queues {
+		for col := range mikrotikQueueCounters[q.Kind] {
+			oid = append(oid, fmt.Sprintf("%s.%s", col, q.OIDTail))
+		}
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	ts := time.Now().UTC()
+	for _, variable := range vars {
+		oid := strings.Split(variable.Name, ".")
+		oidTail := oid[len(oid)-1]
+		oidHead := strings.TrimSuffix(variable.Name, "."+oidTail)
+		for kind, cols := range mikrotikQueueCounters {
+			name, ok := cols[oidHead]
+			if !ok {
+				continue
+			}
+			q := m.queues[index[kind][oidTail]]
+			if q == nil {
+				break
+			}
+			if verbose {
+				log.Printf("processing SNMP response for %s queue %s %s from %s\n", kind, q.Name, name, t.Name)
+			}
+			q.Counter(name).Update(gosnmp.ToBigInt(variable.Value), ts)
+			break
+		}
+	}
+	return nil
+}
+
+// queueSelected reports if the queue name matches one of the queue patterns configured
+func (m *mikrotik) queueSelected(name string) bool {
+	for _, re := range m.queuePatterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// reindexQueues reconciles the tracked queues with those found in the latest walk of the queue tables.
+// Queues that have moved index have their counter baselines reset and those that have been removed are dropped.
+func (m *mikrotik) reindexQueues(t *target.Target, index map[string]map[string]string, verbose bool) {
+	found := make(map[string]bool)
+	for kind, idx := range index {
+		for oidTail, key := range idx {
+			found[key] = true
+			q := m.queues[key]
+			if q != nil && q.OIDTail == oidTail {
+				continue
+			}
+			if q != nil {
+				log.Printf("%s queue %s on %s has moved from index %s to %s, resetting counters\n", kind, q.Name, t.Name, q.OIDTail, oidTail)
+			} else if verbose {
+				log.Printf("%s queue %s on %s added for tracking\n", kind, key, t.Name)
+			}
+			m.queues[key] = info.NewQueue(strings.TrimPrefix(key, kind+"/"), kind, oidTail)
+		}
+	}
+	for key := range m.queues {
+		if !found[key] {
+			if verbose {
+				log.Printf("queue %s on %s is no longer present and has been dropped\n", key, t.Name)
+			}
+			delete(m.queues, key)
+		}
+	}
+}
+
+// queueMetrics returns the rate of each counter of the queues being monitored
+func (m *mikrotik) queueMetrics() (metrics []*info.Metric) {
+	for _, q := range m.queues {
+		for name, c := range q.Counters {
+			unit := "1/s"
+			if strings.HasPrefix(name, "bytes") {
+				unit = "By/s"
+			}
+			metrics = append(metrics, &info.Metric{
+				Type:        fmt.Sprintf("queue/%s/%s/%s", q.Kind, info.MetricName(q.Name), name),
+				Description: fmt.Sprintf("%s queue %s %s rate", q.Kind, q.Name, name),
+				Unit:        unit,
+				Value:       c.Rate(),
+			})
+		}
+	}
+	return
+}
@@ -0,0 +1,263 @@
+package collect
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// MIKROTIK-MIB mtxrOpticalTable, indexed by ifIndex
+	mikrotikOpticalTemperature   = ".1.3.6.1.4.1.14988.1.1.19.1.1.6"
+	mikrotikOpticalSupplyVoltage = ".1.3.6.1.4.1.14988.1.1.19.1.1.7"
+	mikrotikOpticalTxBiasCurrent = ".1.3.6.1.4.1.14988.1.1.19.1.1.8"
+	mikrotikOpticalTxPower       = ".1.3.6.1.4.1.14988.1.1.19.1.1.9"
+	mikrotikOpticalRxPower       = ".1.3.6.1.4.1.14988.1.1.19.1.1.10"
+	// ENTITY-MIB
+	entPhysicalContainedIn     = ".1.3.6.1.2.1.47.1.1.1.1.4"
+	entAliasMappingIdentifier  = ".1.3.6.1.2.1.47.1.3.2.1.2"
+	ifIndexOid                 = ".1.3.6.1.2.1.2.2.1.1"
+	entityContainmentMaxHeight = 8
+	// CISCO-ENTITY-SENSOR-MIB entSensorValueTable has the same columns as entPhySensorTable
+	ciscoEntSensorEntry = ".1.3.6.1.4.1.9.9.91.1.1.1.1"
+)
+
+// opticalSensorTables are the sensor tables, indexed by entPhysicalIndex, searched for transceiver sensors
+var opticalSensorTables = []string{entPhySensorEntry, ciscoEntSensorEntry}
+
+func init() {
+	target.RegisterExtension("Optical", target.NoOptions(newOptical))
+}
+
+// optical is the extension collecting the digital optical monitoring values of the SFP transceivers of the
+// interfaces being tracked. The values are held on the interfaces they belong to.
+type optical struct{}
+
+func newOptical() target.Extension {
+	return new(optical)
+}
+
+// Collect reads the transceivers of the interfaces found by the interface collection that runs before the
+// extensions. The Mikrotik mtxrOpticalTable is used where the target has it, otherwise the sensors in
+// ENTITY-SENSOR-MIB or CISCO-ENTITY-SENSOR-MIB that belong to the physical entity of an interface are used.
+func (o *optical) Collect(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	// ifIndex : Optical
+	readings := make(map[string]*info.Optical)
+	err = mikrotikOptical(t, readings, verbose)
+	if err != nil {
+		return err
+	}
+	if len(readings) == 0 {
+		err = entityOptical(t, readings, verbose)
+		if err != nil {
+			return err
+		}
+	}
+	ts := time.Now().UTC()
+	for ifIndex, descr := range t.IfaceIndex {
+		iface := t.Ifaces[descr]
+		if iface == nil {
+			continue
+		}
+		iface.Optical = readings[ifIndex]
+		if iface.Optical != nil {
+			iface.Optical.Timestamp = ts
+		}
+	}
+	return nil
+}
+
+// opticalMetrics are the digital optical monitoring values published for an interface's transceiver
+var opticalMetrics = []struct {
+	name  string
+	unit  string
+	descr string
+	value func(o *info.Optical) *float64
+}{
+	{"rxpower", "dBm", "optical Rx power", func(o *info.Optical) *float64 { return o.RxPower }},
+	{"txpower", "dBm", "optical Tx power", func(o *info.Optical) *float64 { return o.TxPower }},
+	{"bias", "mA", "laser bias current", func(o *info.Optical) *float64 { return o.Bias }},
+	{"temperature", "Cel", "transceiver temperature", func(o *info.Optical) *float64 { return o.Temperature }},
+	{"voltage", "V", "transceiver voltage", func(o *info.Optical) *float64 { return o.Voltage }},
+}
+
+// Metrics returns the values each interface's transceiver reports, those it does not report are not published.
+// They are published under the same interface name as the interface's traffic.
+func (o *optical) Metrics(t *target.Target) (metrics []*info.Metric) {
+	for iface, i := range t.Ifaces {
+		if i == nil || i.Optical == nil {
+			continue
+		}
+		for _, m := range opticalMetrics {
+			v := m.value(i.Optical)
+			if v == nil {
+				continue
+			}
+			metrics = append(metrics, &info.Metric{
+				Type:        fmt.Sprintf("interface/%s/optical/%s", info.IfaceName(iface), m.name),
+				Description: fmt.Sprintf("%s %s", iface, m.descr),
+				Unit:        m.unit,
+				Value:       *v,
+			})
+		}
+	}
+	return
+}
+
+// mikrotikOptical reads the mtxrOpticalTable row of each interface being tracked
+func mikrotikOptical(t *target.Target, readings map[string]*info.Optical, verbose bool) error {
+	var oid []string
+	for ifIndex, descr := range t.IfaceIndex {
+		if t.Ifaces[descr] == nil {
+			continue
+		}
+		for _, col := range []string{mikrotikOpticalTemperature, mikrotikOpticalSupplyVoltage,
+			mikrotikOpticalTxBiasCurrent, mikrotikOpticalTxPower, mikrotikOpticalRxPower} {
+			oid = append(oid, fmt.Sprintf("%s.%s", col, ifIndex))
+		}
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	for _, variable := range vars {
+		if variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		o := strings.Split(variable.Name, ".")
+		ifIndex := o[len(o)-1]
+		r, ok := readings[ifIndex]
+		if !ok {
+			r = new(info.Optical)
+			readings[ifIndex] = r
+		}
+		if verbose {
+			log.Printf("processing SNMP response for %s from %s for interface %s\n", variable.Name, t.Name, t.IfaceIndex[ifIndex])
+		}
+		v := float64(gosnmp.ToBigInt(variable.Value).Int64())
+		switch strings.TrimSuffix(variable.Name, "."+ifIndex) {
+		case mikrotikOpticalTemperature:
+			r.Temperature = opticalValue(v)
+		case mikrotikOpticalSupplyVoltage:
+			r.Voltage = opticalValue(v / 1000)
+		case mikrotikOpticalTxBiasCurrent:
+			r.Bias = opticalValue(v)
+		case mikrotikOpticalTxPower:
+			r.TxPower = opticalValue(v / 1000)
+		case mikrotikOpticalRxPower:
+			r.RxPower = opticalValue(v / 1000)
+		}
+	}
+	return nil
+}
+
+// entityOptical finds the sensors contained within the physical entity of each interface being tracked.
+// Physical entities are associated to interfaces by entAliasMappingTable.
+func entityOptical(t *target.Target, readings map[string]*info.Optical, verbose bool) error {
+	aliases, err := walkTableColumn(t, entAliasMappingIdentifier)
+	if err != nil {
+		return err
+	}
+	// entPhysicalIndex : ifIndex for the interfaces being tracked
+	ports := make(map[string]string)
+	for idx, v := range aliases {
+		ifIndex := strings.TrimPrefix(pduString(v), ifIndexOid+".")
+		if t.Ifaces[t.IfaceIndex[ifIndex]] == nil {
+			continue
+		}
+		// index is entPhysicalIndex.entAliasLogicalIndexOrZero
+		ports[strings.SplitN(idx, ".", 2)[0]] = ifIndex
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+	containedIn, err := walkTableColumn(t, entPhysicalContainedIn)
+	if err != nil {
+		return err
+	}
+	// port returns the ifIndex of the interface whose entity contains the entity, transceiver sensors are
+	// usually children of the transceiver which is itself a child of, or aliased as, the port
+	port := func(entity string) (string, bool) {
+		for i := 0; i < entityContainmentMaxHeight && entity != "0" && entity != ""; i++ {
+			if ifIndex, ok := ports[entity]; ok {
+				return ifIndex, true
+			}
+			entity = gosnmp.ToBigInt(containedIn[entity]).String()
+		}
+		return "", false
+	}
+	for _, table := range opticalSensorTables {
+		values, err := walkTableColumn(t, table+".4")
+		if err != nil {
+			return err
+		}
+		sensors := make(map[string]string) // entPhysicalIndex : ifIndex
+		var oid []string
+		for idx := range values {
+			ifIndex, ok := port(idx)
+			if !ok {
+				continue
+			}
+			sensors[idx] = ifIndex
+			for _, col := range []string{table + ".1", table + ".2", table + ".3", table + ".5", entPhysicalName} {
+				oid = append(oid, fmt.Sprintf("%s.%s", col, idx))
+			}
+		}
+		vars, err := get(t, oid)
+		if err != nil {
+			return err
+		}
+		columns := make(map[string]interface{})
+		for _, variable := range vars {
+			columns[variable.Name] = variable.Value
+		}
+		col := func(c, idx string) interface{} {
+			return columns[fmt.Sprintf("%s.%s", c, idx)]
+		}
+		for idx, ifIndex := range sensors {
+			if gosnmp.ToBigInt(col(table+".5", idx)).Int64() != entPhySensorOperStatusOK {
+				continue
+			}
+			name := strings.ToLower(pduString(col(entPhysicalName, idx)))
+			v := entitySensorValue(values[idx], col(table+".2", idx), col(table+".3", idx))
+			r, ok := readings[ifIndex]
+			if !ok {
+				r = new(info.Optical)
+				readings[ifIndex] = r
+			}
+			if verbose {
+				log.Printf("processing transceiver sensor %s from %s for interface %s\n", name, t.Name, t.IfaceIndex[ifIndex])
+			}
+			switch entPhySensorTypes[gosnmp.ToBigInt(col(table+".1", idx)).Int64()].typ {
+			case "optical-power":
+				if strings.Contains(name, "rx") || strings.Contains(name, "receive") {
+					r.RxPower = opticalValue(v)
+				} else if strings.Contains(name, "tx") || strings.Contains(name, "transmit") {
+					r.TxPower = opticalValue(v)
+				}
+			case "current":
+				r.Bias = opticalValue(v * 1000)
+			case "temperature":
+				r.Temperature = opticalValue(v)
+			case "voltage-dc":
+				r.Voltage = opticalValue(v)
+			}
+		}
+	}
+	return nil
+}
+
+// opticalValue returns a reading for the optional fields of info.Optical
+func opticalValue(v float64) *float64 {
+	return &v
+}
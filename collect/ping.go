@@ -0,0 +1,196 @@
+package collect
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	pingCount    = 5
+	pingInterval = 200 * time.Millisecond
+	pingTimeout  = time.Second
+	// protocol numbers for parsing ICMP messages
+	protocolICMP     = 1
+	protocolIPv6ICMP = 58
+)
+
+func init() {
+	target.RegisterExtension("Ping", target.NoOptions(newPing))
+}
+
+// ping is the extension probing the reachability and latency of the target with ICMP echo requests, so that a
+// target that is down can be told apart from one where only SNMP is failing
+type ping struct {
+	stats *info.Ping
+}
+
+func newPing() target.Extension {
+	return &ping{stats: new(info.Ping)}
+}
+
+// Collect sends ICMP echo requests to the target and records the replies and their round trip times.
+// An unprivileged datagram socket is used where the system allows it, otherwise a raw socket which requires
+// the process to have the privilege to open one.
+func (pg *ping) Collect(t *target.Target, verbose bool) error {
+	ip := net.ParseIP(t.IP)
+	if ip == nil {
+		addr, err := net.ResolveIPAddr("ip", t.IP)
+		if err != nil {
+			return err
+		}
+		ip = addr.IP
+	}
+	conn, dst, err := pingListen(ip)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	proto := protocolICMP
+	var typ icmp.Type = ipv4.ICMPTypeEcho
+	if ip.To4() == nil {
+		proto = protocolIPv6ICMP
+		typ = ipv6.ICMPTypeEchoRequest
+	}
+	// the ID is replaced by the kernel on datagram sockets so replies are matched on their sequence number
+	id := os.Getpid() & 0xffff
+	p := &info.Ping{Sent: pingCount}
+	var total time.Duration
+	buf := make([]byte, 1500)
+	for seq := 1; seq <= pingCount; seq++ {
+		if seq > 1 {
+			time.Sleep(pingInterval)
+		}
+		msg := icmp.Message{
+			Type: typ,
+			Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("snmpgcpmonitoring")},
+		}
+		// a request that cannot be sent is counted as lost so an unreachable target is still reported
+		b, err := msg.Marshal(nil)
+		if err != nil {
+			if verbose {
+				log.Printf("could not create ICMP echo request to %s for sequence %d: %v\n", t.Name, seq, err)
+			}
+			continue
+		}
+		start := time.Now()
+		_, err = conn.WriteTo(b, dst)
+		if err != nil {
+			if verbose {
+				log.Printf("could not send ICMP echo request to %s for sequence %d: %v\n", t.Name, seq, err)
+			}
+			continue
+		}
+		rtt, ok := pingReply(conn, proto, ip, seq, start, buf)
+		if !ok {
+			if verbose {
+				log.Printf("no ICMP echo reply from %s for sequence %d\n", t.Name, seq)
+			}
+			continue
+		}
+		if verbose {
+			log.Printf("processing ICMP echo reply from %s for sequence %d in %v\n", t.Name, seq, rtt)
+		}
+		p.Received++
+		total += rtt
+		if p.Min == 0 || rtt < p.Min {
+			p.Min = rtt
+		}
+		if rtt > p.Max {
+			p.Max = rtt
+		}
+	}
+	if p.Received > 0 {
+		p.Avg = total / time.Duration(p.Received)
+	}
+	p.Timestamp = time.Now().UTC()
+	pg.stats = p
+	return nil
+}
+
+// Metrics returns the reachability and packet loss of the target and the round trip times when it is reachable
+func (pg *ping) Metrics(t *target.Target) (metrics []*info.Metric) {
+	p := pg.stats
+	if p.Timestamp.IsZero() {
+		return
+	}
+	var reachable int64
+	if p.Reachable() {
+		reachable = 1
+	}
+	metrics = append(metrics,
+		&info.Metric{Type: "ping/reachable", Description: "ICMP reachability, 1 if reachable", Unit: "1", Value: reachable},
+		&info.Metric{Type: "ping/loss", Description: "ICMP packet loss", Unit: "%", Value: p.Loss()},
+	)
+	// there are no round trip times when the target is unreachable
+	if p.Reachable() {
+		metrics = append(metrics,
+			&info.Metric{Type: "ping/rtt/min", Description: "ICMP min round trip time", Unit: "ms", Value: p.Min.Seconds() * 1000},
+			&info.Metric{Type: "ping/rtt/avg", Description: "ICMP avg round trip time", Unit: "ms", Value: p.Avg.Seconds() * 1000},
+			&info.Metric{Type: "ping/rtt/max", Description: "ICMP max round trip time", Unit: "ms", Value: p.Max.Seconds() * 1000},
+		)
+	}
+	return
+}
+
+// pingListen opens an unprivileged ICMP datagram socket, falling back to a raw socket, and returns it with the
+// address to send the echo requests to
+func pingListen(ip net.IP) (*icmp.PacketConn, net.Addr, error) {
+	network, raw, laddr := "udp4", "ip4:icmp", "0.0.0.0"
+	if ip.To4() == nil {
+		network, raw, laddr = "udp6", "ip6:ipv6-icmp", "::"
+	}
+	conn, err := icmp.ListenPacket(network, laddr)
+	if err == nil {
+		return conn, &net.UDPAddr{IP: ip}, nil
+	}
+	conn, rerr := icmp.ListenPacket(raw, laddr)
+	if rerr != nil {
+		return nil, nil, fmt.Errorf("could not open ICMP socket: %v, %v", err, rerr)
+	}
+	return conn, &net.IPAddr{IP: ip}, nil
+}
+
+// pingReply waits for the echo reply with the sequence number from the IP address, ignoring any other ICMP
+// messages received, and returns its round trip time
+func pingReply(conn *icmp.PacketConn, proto int, ip net.IP, seq int, start time.Time, buf []byte) (time.Duration, bool) {
+	err := conn.SetReadDeadline(start.Add(pingTimeout))
+	if err != nil {
+		return 0, false
+	}
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return 0, false
+		}
+		rtt := time.Since(start)
+		var from net.IP
+		switch a := peer.(type) {
+		case *net.UDPAddr:
+			from = a.IP
+		case *net.IPAddr:
+			from = a.IP
+		}
+		if !from.Equal(ip) {
+			continue
+		}
+		msg, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			continue
+		}
+		if msg.Type != ipv4.ICMPTypeEchoReply && msg.Type != ipv6.ICMPTypeEchoReply {
+			continue
+		}
+		if echo, ok := msg.Body.(*icmp.Echo); ok && echo.Seq == seq {
+			return rtt, true
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package collect
+
+import (
+	"log"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// Printer-MIB prtMarkerSuppliesTable, indexed by hrDeviceIndex.prtMarkerSuppliesIndex
+	prtMarkerSuppliesType        = ".1.3.6.1.2.1.43.11.1.1.5"
+	prtMarkerSuppliesDescription = ".1.3.6.1.2.1.43.11.1.1.6"
+	prtMarkerSuppliesMaxCapacity = ".1.3.6.1.2.1.43.11.1.1.8"
+	prtMarkerSuppliesLevel       = ".1.3.6.1.2.1.43.11.1.1.9"
+	// Printer-MIB prtMarkerTable, indexed by hrDeviceIndex.prtMarkerIndex
+	prtMarkerCounterUnit = ".1.3.6.1.2.1.43.10.2.1.3"
+	prtMarkerLifeCount   = ".1.3.6.1.2.1.43.10.2.1.4"
+
+	// the prtMarkerCounterUnit values of markers counting pages
+	prtMarkerCounterImpressions = 7
+	prtMarkerCounterSheets      = 8
+)
+
+// prtMarkerSuppliesTypes maps the PrtMarkerSuppliesTypeTC values to names
+var prtMarkerSuppliesTypes = map[int64]string{
+	1:  "other",
+	2:  "unknown",
+	3:  "toner",
+	4:  "wastetoner",
+	5:  "ink",
+	6:  "inkcartridge",
+	7:  "inkribbon",
+	8:  "wasteink",
+	9:  "drum",
+	10: "developer",
+	11: "fuseroil",
+	12: "solidwax",
+	13: "ribbonwax",
+	14: "wastewax",
+	15: "fuser",
+	16: "coronawire",
+	17: "fuseroilwick",
+	18: "cleanerunit",
+	19: "fusercleaningpad",
+	20: "transferunit",
+	21: "tonercartridge",
+	22: "fuseroiler",
+	23: "water",
+	24: "wastewater",
+	25: "gluewateradditive",
+	26: "wastepaper",
+	27: "bindingsupply",
+	28: "bandingsupply",
+	29: "stitchingwire",
+	30: "shrinkwrap",
+	31: "paperwrap",
+	32: "staples",
+	33: "inserts",
+	34: "covers",
+}
+
+func init() {
+	target.RegisterExtension("Printer", target.NoOptions(newPrinter))
+}
+
+// printer is the extension collecting the supply levels and page counters of a printer from Printer-MIB
+type printer struct {
+	printer *info.Printer
+}
+
+func newPrinter() target.Extension {
+	return &printer{printer: info.NewPrinter()}
+}
+
+// Collect walks the marker supplies and marker tables. Supplies that do not report both a level and a maximum
+// capacity cannot be given as a percentage so are not collected, nor are markers that do not count pages.
+func (p *printer) Collect(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	levels, err := walkTableColumn(t, prtMarkerSuppliesLevel)
+	if err != nil {
+		return err
+	}
+	prt := info.NewPrinter()
+	if len(levels) > 0 {
+		capacity, err := walkTableColumn(t, prtMarkerSuppliesMaxCapacity)
+		if err != nil {
+			return err
+		}
+		descrs, err := walkTableColumn(t, prtMarkerSuppliesDescription)
+		if err != nil {
+			return err
+		}
+		types, err := walkTableColumn(t, prtMarkerSuppliesType)
+		if err != nil {
+			return err
+		}
+		for idx, v := range levels {
+			// negative values mean the level or capacity is unknown or only that some remains
+			level := gosnmp.ToBigInt(v).Int64()
+			max := gosnmp.ToBigInt(capacity[idx]).Int64()
+			descr := pduString(descrs[idx])
+			if level < 0 || max <= 0 {
+				if verbose {
+					log.Printf("printer supply %s on %s does not report its level\n", descr, t.Name)
+				}
+				continue
+			}
+			if verbose {
+				log.Printf("processing printer supply %s from %s\n", descr, t.Name)
+			}
+			typ, ok := prtMarkerSuppliesTypes[gosnmp.ToBigInt(types[idx]).Int64()]
+			if !ok {
+				typ = prtMarkerSuppliesTypes[1]
+			}
+			prt.Supplies[idx] = &info.PrinterSupply{
+				Description: descr,
+				Type:        typ,
+				Level:       float64(level) / float64(max) * 100,
+			}
+		}
+	}
+	counts, err := walkTableColumn(t, prtMarkerLifeCount)
+	if err != nil {
+		return err
+	}
+	if len(counts) > 0 {
+		units, err := walkTableColumn(t, prtMarkerCounterUnit)
+		if err != nil {
+			return err
+		}
+		for idx, v := range counts {
+			unit := gosnmp.ToBigInt(units[idx]).Int64()
+			if unit != prtMarkerCounterImpressions && unit != prtMarkerCounterSheets {
+				continue
+			}
+			if verbose {
+				log.Printf("processing printer marker %s page count from %s\n", idx, t.Name)
+			}
+			prt.Pages[idx] = gosnmp.ToBigInt(v).Int64()
+		}
+	}
+	prt.Timestamp = time.Now().UTC()
+	p.printer = prt
+	return nil
+}
+
+// Metrics returns the level of each supply labelled with its index, description and type and the page count of
+// each marker labelled with its index. The index keeps supplies sharing a description and type, such as two
+// identical toner cartridges, as separate series.
+func (p *printer) Metrics(t *target.Target) (metrics []*info.Metric) {
+	for idx, s := range p.printer.Supplies {
+		metrics = append(metrics, &info.Metric{
+			Type:        "printer/supplies/level",
+			Description: "printer supply level of its maximum capacity",
+			Unit:        "%",
+			Labels: map[string]string{
+				"index":       idx,
+				"description": s.Description,
+				"type":        s.Type,
+			},
+			Value: s.Level,
+		})
+	}
+	for idx, n := range p.printer.Pages {
+		metrics = append(metrics, &info.Metric{
+			Type:        "printer/pages",
+			Description: "printer marker life time page count",
+			Unit:        "1",
+			Labels: map[string]string{
+				"marker": idx,
+			},
+			Value: n,
+		})
+	}
+	return
+}
@@ -0,0 +1,155 @@
+package collect
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// HOST-RESOURCES-MIB hrSWRunTable and hrSWRunPerfTable, indexed by hrSWRunIndex
+	hrSWRunName    = ".1.3.6.1.2.1.25.4.2.1.2"
+	hrSWRunStatus  = ".1.3.6.1.2.1.25.4.2.1.7"
+	hrSWRunPerfCPU = ".1.3.6.1.2.1.25.5.1.1.1"
+	hrSWRunPerfMem = ".1.3.6.1.2.1.25.5.1.1.2"
+
+	// the hrSWRunStatus value of processes that are no longer loaded
+	hrSWRunStatusInvalid = 4
+)
+
+func init() {
+	target.RegisterExtension("Processes", newProcesses)
+}
+
+// processes is the extension monitoring groups of the running processes of a host
+type processes struct {
+	// Groups are regular expressions matched against the hrSWRunName of running processes keyed by the name of
+	// the group of processes they select
+	Groups map[string]string
+	match  map[string]*regexp.Regexp
+	groups map[string]*info.ProcessGroup
+}
+
+func newProcesses(data json.RawMessage) (target.Extension, error) {
+	p := new(processes)
+	err := json.Unmarshal(data, p)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Groups) == 0 {
+		return nil, errors.New("no process groups configured")
+	}
+	p.match = make(map[string]*regexp.Regexp)
+	p.groups = make(map[string]*info.ProcessGroup)
+	for group, pattern := range p.Groups {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s for process group %s: %v", pattern, group, err)
+		}
+		p.match[group] = re
+		p.groups[group] = info.NewProcessGroup(group)
+	}
+	return p, nil
+}
+
+// Collect collects the number, CPU time and memory of the running processes in each process group.
+// A process is counted in every group whose pattern matches its name.
+func (p *processes) Collect(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	names, err := walkTableColumn(t, hrSWRunName)
+	if err != nil {
+		return err
+	}
+	// a host always has running processes so none means the agent does not implement HOST-RESOURCES-MIB or its
+	// view excludes it, the groups are left unchanged rather than reported as missing
+	if len(names) == 0 {
+		return errors.New("no processes returned from hrSWRunTable")
+	}
+	status, err := walkTableColumn(t, hrSWRunStatus)
+	if err != nil {
+		return err
+	}
+	cpu, err := walkTableColumn(t, hrSWRunPerfCPU)
+	if err != nil {
+		return err
+	}
+	mem, err := walkTableColumn(t, hrSWRunPerfMem)
+	if err != nil {
+		return err
+	}
+	ts := time.Now().UTC()
+	count := make(map[string]int64)
+	// the CPU time counters of the processes running in each group keyed by hrSWRunIndex
+	cpuTime := make(map[string]map[string]*info.Counter)
+	memTotal := make(map[string]int64)
+	for group := range p.match {
+		cpuTime[group] = make(map[string]*info.Counter)
+	}
+	for idx, v := range names {
+		if gosnmp.ToBigInt(status[idx]).Int64() == hrSWRunStatusInvalid {
+			continue
+		}
+		name := pduString(v)
+		for group, re := range p.match {
+			if !re.MatchString(name) {
+				continue
+			}
+			if verbose {
+				log.Printf("processing process %s (%s) from %s for group %s\n", name, idx, t.Name, group)
+			}
+			count[group]++
+			c, ok := p.groups[group].CPU[idx]
+			if !ok {
+				c = info.NewCounter()
+			}
+			c.Update(gosnmp.ToBigInt(cpu[idx]), ts)
+			cpuTime[group][idx] = c
+			// hrSWRunPerfMem is in KBytes
+			memTotal[group] += gosnmp.ToBigInt(mem[idx]).Int64() * 1024
+		}
+	}
+	for group := range p.match {
+		g := p.groups[group]
+		if g.Count > 0 && count[group] == 0 {
+			log.Printf("no %s processes are running on %s\n", group, t.Name)
+		}
+		g.Count = count[group]
+		g.CPU = cpuTime[group]
+		g.Memory = memTotal[group]
+		g.Timestamp = ts
+	}
+	return nil
+}
+
+// Metrics returns the process count, CPU usage and memory of each process group and whether it is missing
+func (p *processes) Metrics(t *target.Target) (metrics []*info.Metric) {
+	for _, g := range p.groups {
+		if g.Timestamp.IsZero() {
+			continue
+		}
+		var missing int64
+		if g.Missing() {
+			missing = 1
+		}
+		prefix := fmt.Sprintf("processes/%s", info.MetricName(g.Name))
+		descr := fmt.Sprintf("%s processes", g.Name)
+		metrics = append(metrics,
+			&info.Metric{Type: prefix + "/count", Description: descr + " running process count", Unit: "1", Value: g.Count},
+			&info.Metric{Type: prefix + "/cpu", Description: descr + " CPU usage of a single CPU", Unit: "%", Value: g.CPUUsage()},
+			&info.Metric{Type: prefix + "/memory", Description: descr + " memory allocated", Unit: "By", Value: g.Memory},
+			&info.Metric{Type: prefix + "/missing", Description: descr + " not running, 1 if missing", Unit: "1", Value: missing},
+		)
+	}
+	return
+}
@@ -0,0 +1,83 @@
+package collect
+
+import (
+	"log"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// IP-MIB scalars
+	ipForwDatagrams = ".1.3.6.1.2.1.4.6.0"
+	ipInDiscards    = ".1.3.6.1.2.1.4.8.0"
+	ipOutDiscards   = ".1.3.6.1.2.1.4.11.0"
+	// TCP-MIB scalars
+	tcpActiveOpens  = ".1.3.6.1.2.1.6.5.0"
+	tcpPassiveOpens = ".1.3.6.1.2.1.6.6.0"
+	tcpCurrEstab    = ".1.3.6.1.2.1.6.9.0"
+	tcpRetransSegs  = ".1.3.6.1.2.1.6.12.0"
+	// UDP-MIB scalars
+	udpInDatagrams  = ".1.3.6.1.2.1.7.1.0"
+	udpNoPorts      = ".1.3.6.1.2.1.7.2.0"
+	udpInErrors     = ".1.3.6.1.2.1.7.3.0"
+	udpOutDatagrams = ".1.3.6.1.2.1.7.4.0"
+)
+
+// protocolCounters maps the protocol counter OIDs to the names of the counters used in info.Protocols
+var protocolCounters = map[string]string{
+	ipForwDatagrams: "ip/forwarded",
+	ipInDiscards:    "ip/indiscards",
+	ipOutDiscards:   "ip/outdiscards",
+	tcpActiveOpens:  "tcp/activeopens",
+	tcpPassiveOpens: "tcp/passiveopens",
+	tcpRetransSegs:  "tcp/retransmitted",
+	udpInDatagrams:  "udp/indatagrams",
+	udpNoPorts:      "udp/noports",
+	udpInErrors:     "udp/inerrors",
+	udpOutDatagrams: "udp/outdatagrams",
+}
+
+// Protocols collects the IP, TCP and UDP statistics of the target's network stack
+func Protocols(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	oid := []string{tcpCurrEstab}
+	for o := range protocolCounters {
+		oid = append(oid, o)
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	ts := time.Now().UTC()
+	var found bool
+	for _, variable := range vars {
+		if variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		found = true
+		v := gosnmp.ToBigInt(variable.Value)
+		if variable.Name == tcpCurrEstab {
+			if verbose {
+				log.Printf("processing SNMP response for tcpCurrEstab from %s\n", t.Name)
+			}
+			t.Protocols.TCPEstablished = v.Int64()
+			continue
+		}
+		if name, ok := protocolCounters[variable.Name]; ok {
+			if verbose {
+				log.Printf("processing SNMP response for %s from %s\n", name, t.Name)
+			}
+			t.Protocols.Counter(name).Update(v, ts)
+		}
+	}
+	if found {
+		t.Protocols.Timestamp = ts
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package collect
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// BGP4-MIB bgpPeerTable, indexed by bgpPeerRemoteAddr
+	bgpPeerState              = ".1.3.6.1.2.1.15.3.1.2"
+	bgpPeerAdminStatus        = ".1.3.6.1.2.1.15.3.1.3"
+	bgpPeerRemoteAs           = ".1.3.6.1.2.1.15.3.1.9"
+	bgpPeerInUpdates          = ".1.3.6.1.2.1.15.3.1.10"
+	bgpPeerOutUpdates         = ".1.3.6.1.2.1.15.3.1.11"
+	bgpPeerFsmEstablishedTime = ".1.3.6.1.2.1.15.3.1.16"
+	// CISCO-BGP4-MIB cbgpPeerAddrFamilyPrefixTable, indexed by bgpPeerRemoteAddr.afi.safi
+	cbgpPeerAcceptedPrefixes = ".1.3.6.1.4.1.9.9.187.1.2.4.1.1"
+	// OSPF-MIB ospfNbrTable, indexed by ospfNbrIpAddr.ospfNbrAddressLessIndex
+	ospfNbrRtrID = ".1.3.6.1.2.1.14.10.1.3"
+	ospfNbrState = ".1.3.6.1.2.1.14.10.1.6"
+
+	// the bgpPeerAdminStatus value of peers that have been administratively shut down
+	bgpPeerAdminStop = 1
+)
+
+func init() {
+	target.RegisterExtension("Routing", target.NoOptions(newRouting))
+}
+
+// routing is the extension collecting the state of the BGP peers and OSPF neighbours of a router
+type routing struct {
+	bgp  map[string]*info.BGPPeer       // remote address : BGPPeer
+	ospf map[string]*info.OSPFNeighbour // ospfNbrTable index : OSPFNeighbour
+}
+
+func newRouting() target.Extension {
+	return &routing{
+		bgp:  make(map[string]*info.BGPPeer),
+		ospf: make(map[string]*info.OSPFNeighbour),
+	}
+}
+
+// Collect walks the BGP4-MIB peer table and the OSPF-MIB neighbour table.
+// Either table being absent is not an error as routers may run only one of the protocols.
+func (r *routing) Collect(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	err = r.collectBGP(t, verbose)
+	if err != nil {
+		return err
+	}
+	return r.collectOSPF(t, verbose)
+}
+
+// collectBGP updates the BGP peers from bgpPeerTable. Peers that are administratively shut down are not
+// collected so that their sessions being down does not alert. The prefixes received are only available from
+// Cisco targets through CISCO-BGP4-MIB.
+func (r *routing) collectBGP(t *target.Target, verbose bool) error {
+	states, err := walkTableColumn(t, bgpPeerState)
+	if err != nil {
+		return err
+	}
+	cols := make(map[string]map[string]interface{})
+	for _, col := range []string{bgpPeerAdminStatus, bgpPeerRemoteAs, bgpPeerInUpdates, bgpPeerOutUpdates, bgpPeerFsmEstablishedTime} {
+		if len(states) == 0 {
+			break
+		}
+		cols[col], err = walkTableColumn(t, col)
+		if err != nil {
+			return err
+		}
+	}
+	prefixes := make(map[string]int64)
+	// whether the target has cbgpPeerAddrFamilyPrefixTable
+	var cbgp bool
+	if len(states) > 0 {
+		accepted, err := walkTableColumn(t, cbgpPeerAcceptedPrefixes)
+		if err != nil {
+			return err
+		}
+		cbgp = len(accepted) > 0
+		for idx, v := range accepted {
+			// the prefixes accepted from a peer are summed over its address families
+			parts := strings.Split(idx, ".")
+			if len(parts) < 2 {
+				continue
+			}
+			addr := strings.Join(parts[:len(parts)-2], ".")
+			prefixes[addr] += gosnmp.ToBigInt(v).Int64()
+		}
+	}
+	ts := time.Now().UTC()
+	found := make(map[string]bool)
+	for addr, v := range states {
+		if gosnmp.ToBigInt(cols[bgpPeerAdminStatus][addr]).Int64() == bgpPeerAdminStop {
+			if verbose {
+				log.Printf("BGP peer %s on %s is administratively stopped\n", addr, t.Name)
+			}
+			continue
+		}
+		if verbose {
+			log.Printf("processing BGP peer %s from %s\n", addr, t.Name)
+		}
+		p, ok := r.bgp[addr]
+		if !ok {
+			p = info.NewBGPPeer(addr)
+			r.bgp[addr] = p
+		}
+		p.State = gosnmp.ToBigInt(v).Int64()
+		p.RemoteAS = gosnmp.ToBigInt(cols[bgpPeerRemoteAs][addr]).Int64()
+		p.EstablishedTime = gosnmp.ToBigInt(cols[bgpPeerFsmEstablishedTime][addr]).Int64()
+		// a peer whose session is down has no rows in cbgpPeerAddrFamilyPrefixTable so has received no prefixes
+		p.PrefixesReceived = -1
+		if cbgp {
+			p.PrefixesReceived = prefixes[addr]
+		}
+		p.InUpdates.Update(gosnmp.ToBigInt(cols[bgpPeerInUpdates][addr]), ts)
+		p.OutUpdates.Update(gosnmp.ToBigInt(cols[bgpPeerOutUpdates][addr]), ts)
+		p.Timestamp = ts
+		found[addr] = true
+	}
+	for addr := range r.bgp {
+		if !found[addr] {
+			log.Printf("BGP peer %s is no longer configured on %s\n", addr, t.Name)
+			delete(r.bgp, addr)
+		}
+	}
+	return nil
+}
+
+// collectOSPF replaces the OSPF neighbours with those in ospfNbrTable
+func (r *routing) collectOSPF(t *target.Target, verbose bool) error {
+	states, err := walkTableColumn(t, ospfNbrState)
+	if err != nil {
+		return err
+	}
+	var ids map[string]interface{}
+	if len(states) > 0 {
+		ids, err = walkTableColumn(t, ospfNbrRtrID)
+		if err != nil {
+			return err
+		}
+	}
+	ts := time.Now().UTC()
+	nbrs := make(map[string]*info.OSPFNeighbour)
+	for idx, v := range states {
+		// index is ospfNbrIpAddr.ospfNbrAddressLessIndex
+		parts := strings.Split(idx, ".")
+		if len(parts) != 5 {
+			continue
+		}
+		n := &info.OSPFNeighbour{
+			Address:   strings.Join(parts[:4], "."),
+			RouterID:  pduString(ids[idx]),
+			State:     gosnmp.ToBigInt(v).Int64(),
+			Timestamp: ts,
+		}
+		if verbose {
+			log.Printf("processing OSPF neighbour %s from %s\n", n.Address, t.Name)
+		}
+		if prev, ok := r.ospf[idx]; ok && prev.State != n.State {
+			log.Printf("OSPF neighbour %s on %s has changed state from %d to %d\n", n.Address, t.Name, prev.State, n.State)
+		}
+		nbrs[idx] = n
+	}
+	for idx, n := range r.ospf {
+		if _, ok := nbrs[idx]; !ok {
+			log.Printf("OSPF neighbour %s is no longer present on %s\n", n.Address, t.Name)
+		}
+	}
+	r.ospf = nbrs
+	return nil
+}
+
+// Metrics returns the state of each BGP peer and OSPF neighbour as gauges labelled with the peer
+func (r *routing) Metrics(t *target.Target) (metrics []*info.Metric) {
+	for _, p := range r.bgp {
+		labels := map[string]string{
+			"peer":      p.Address,
+			"remote_as": strconv.FormatInt(p.RemoteAS, 10),
+		}
+		metrics = append(metrics,
+			&info.Metric{
+				Type:        "bgp/peers/state",
+				Description: "BGP peer state, 6 is established",
+				Unit:        "1",
+				Labels:      labels,
+				Value:       p.State,
+			},
+			&info.Metric{
+				Type:        "bgp/peers/establishedtime",
+				Description: "BGP peer time in the established state",
+				Unit:        "s",
+				Labels:      labels,
+				Value:       p.EstablishedTime,
+			},
+			&info.Metric{
+				Type:        "bgp/peers/updates/in",
+				Description: "BGP peer update messages received rate",
+				Unit:        "1/s",
+				Labels:      labels,
+				Value:       p.InUpdates.Rate(),
+			},
+			&info.Metric{
+				Type:        "bgp/peers/updates/out",
+				Description: "BGP peer update messages sent rate",
+				Unit:        "1/s",
+				Labels:      labels,
+				Value:       p.OutUpdates.Rate(),
+			},
+		)
+		if p.PrefixesReceived >= 0 {
+			metrics = append(metrics, &info.Metric{
+				Type:        "bgp/peers/prefixes/received",
+				Description: "BGP peer prefixes received",
+				Unit:        "1",
+				Labels:      labels,
+				Value:       p.PrefixesReceived,
+			})
+		}
+	}
+	for _, n := range r.ospf {
+		metrics = append(metrics, &info.Metric{
+			Type:        "ospf/neighbours/state",
+			Description: "OSPF neighbour state, 8 is full",
+			Unit:        "1",
+			Labels: map[string]string{
+				"neighbour": n.Address,
+				"router_id": n.RouterID,
+			},
+			Value: n.State,
+		})
+	}
+	return
+}
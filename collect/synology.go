@@ -0,0 +1,300 @@
+package collect
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// SYNOLOGY-SYSTEM-MIB scalars
+	synologySystemStatus    = ".1.3.6.1.4.1.6574.1.1.0"
+	synologyTemperature     = ".1.3.6.1.4.1.6574.1.2.0"
+	synologyPowerStatus     = ".1.3.6.1.4.1.6574.1.3.0"
+	synologySystemFanStatus = ".1.3.6.1.4.1.6574.1.4.1.0"
+	synologyCPUFanStatus    = ".1.3.6.1.4.1.6574.1.4.2.0"
+	// SYNOLOGY-DISK-MIB diskTable
+	synologyDiskID          = ".1.3.6.1.4.1.6574.2.1.1.2"
+	synologyDiskModel       = ".1.3.6.1.4.1.6574.2.1.1.3"
+	synologyDiskType        = ".1.3.6.1.4.1.6574.2.1.1.4"
+	synologyDiskStatus      = ".1.3.6.1.4.1.6574.2.1.1.5"
+	synologyDiskTemperature = ".1.3.6.1.4.1.6574.2.1.1.6"
+	// SYNOLOGY-RAID-MIB raidTable
+	synologyRaidName      = ".1.3.6.1.4.1.6574.3.1.1.2"
+	synologyRaidStatus    = ".1.3.6.1.4.1.6574.3.1.1.3"
+	synologyRaidFreeSize  = ".1.3.6.1.4.1.6574.3.1.1.4"
+	synologyRaidTotalSize = ".1.3.6.1.4.1.6574.3.1.1.5"
+)
+
+// synologySystemSensors are the SYNOLOGY-SYSTEM-MIB scalars published as health sensors.
+// The status values are 1 for normal and 2 for failed.
+var synologySystemSensors = map[string]healthSensor{
+	synologySystemStatus:    {"system-state", "state", "1", 1},
+	synologyTemperature:     {"system-temperature", "temperature", "Cel", 1},
+	synologyPowerStatus:     {"power-state", "state", "1", 1},
+	synologySystemFanStatus: {"system-fan-state", "state", "1", 1},
+	synologyCPUFanStatus:    {"cpu-fan-state", "state", "1", 1},
+}
+
+// synologyVolume matches the RAID names of volumes, e.g. "Volume 1", which are mounted at /volume1
+var synologyVolume = regexp.MustCompile(`^Volume (\d+)$`)
+
+func init() {
+	target.RegisterExtension("Synology", target.NoOptions(newSynology))
+}
+
+// synology is the extension collecting the disk, RAID and system health of Synology NAS devices
+type synology struct {
+	health map[string]*info.Sensor // name : Sensor
+	disks  map[string]*info.Disk   // diskID : Disk
+	raids  map[string]*info.RAID   // raidName : RAID
+}
+
+func newSynology() target.Extension {
+	return new(synology)
+}
+
+// Collect polls the system, disk and RAID MIBs, an error in one does not stop the others being polled
+func (s *synology) Collect(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	var errs []string
+	err = s.collectSystem(t, verbose)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("system: %v", err))
+	}
+	err = s.collectDisks(t, verbose)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("disk: %v", err))
+	}
+	err = s.collectRAID(t, verbose)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("raid: %v", err))
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// collectSystem collects the system temperature and the system, power and fan states
+func (s *synology) collectSystem(t *target.Target, verbose bool) error {
+	var oid []string
+	for o := range synologySystemSensors {
+		oid = append(oid, o)
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	health := make(map[string]*info.Sensor)
+	ts := time.Now().UTC()
+	for _, variable := range vars {
+		if variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		sensor, ok := synologySystemSensors[variable.Name]
+		if !ok {
+			continue
+		}
+		if verbose {
+			log.Printf("processing synology %s from %s\n", sensor.name, t.Name)
+		}
+		health[sensor.name] = &info.Sensor{
+			Name:      sensor.name,
+			Type:      sensor.typ,
+			Unit:      sensor.unit,
+			Value:     float64(gosnmp.ToBigInt(variable.Value).Int64()) * sensor.scale,
+			Timestamp: ts,
+		}
+	}
+	s.health = health
+	return nil
+}
+
+// collectDisks collects the status and temperature of each disk from diskTable
+func (s *synology) collectDisks(t *target.Target, verbose bool) error {
+	ids, err := walkTableColumn(t, synologyDiskID)
+	if err != nil {
+		return err
+	}
+	var oid []string
+	for idx := range ids {
+		for _, col := range []string{synologyDiskModel, synologyDiskType, synologyDiskStatus, synologyDiskTemperature} {
+			oid = append(oid, fmt.Sprintf("%s.%s", col, idx))
+		}
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	disks := make(map[string]*info.Disk)
+	byIndex := make(map[string]*info.Disk)
+	for idx, id := range ids {
+		d := &info.Disk{ID: strings.TrimSpace(pduString(id))}
+		disks[d.ID] = d
+		byIndex[idx] = d
+	}
+	for _, variable := range vars {
+		o := strings.Split(variable.Name, ".")
+		idx := o[len(o)-1]
+		d := byIndex[idx]
+		if d == nil {
+			continue
+		}
+		if verbose {
+			log.Printf("processing SNMP response for %s from %s for disk %s\n", variable.Name, t.Name, d.ID)
+		}
+		switch strings.TrimSuffix(variable.Name, "."+idx) {
+		case synologyDiskModel:
+			d.Model = strings.TrimSpace(pduString(variable.Value))
+		case synologyDiskType:
+			d.Type = pduString(variable.Value)
+		case synologyDiskStatus:
+			d.Status = gosnmp.ToBigInt(variable.Value).Int64()
+		case synologyDiskTemperature:
+			d.Temperature = gosnmp.ToBigInt(variable.Value).Int64()
+		}
+	}
+	s.disks = disks
+	return nil
+}
+
+// collectRAID collects the status and capacity of each RAID from raidTable.
+// Where the target also reports the volume of a RAID in hrStorage the capacity from hrStorage is used so it
+// matches the storage metrics.
+func (s *synology) collectRAID(t *target.Target, verbose bool) error {
+	names, err := walkTableColumn(t, synologyRaidName)
+	if err != nil {
+		return err
+	}
+	var oid []string
+	for idx := range names {
+		for _, col := range []string{synologyRaidStatus, synologyRaidFreeSize, synologyRaidTotalSize} {
+			oid = append(oid, fmt.Sprintf("%s.%s", col, idx))
+		}
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]interface{})
+	for _, variable := range vars {
+		values[variable.Name] = variable.Value
+	}
+	raids := make(map[string]*info.RAID)
+	mounts := make(map[string]string) // RAID name : volume mount point
+	for idx, n := range names {
+		name := pduString(n)
+		if verbose {
+			log.Printf("processing synology RAID %s from %s\n", name, t.Name)
+		}
+		r := info.NewRAID(name,
+			gosnmp.ToBigInt(values[fmt.Sprintf("%s.%s", synologyRaidStatus, idx)]).Int64(),
+			gosnmp.ToBigInt(values[fmt.Sprintf("%s.%s", synologyRaidFreeSize, idx)]),
+			gosnmp.ToBigInt(values[fmt.Sprintf("%s.%s", synologyRaidTotalSize, idx)]))
+		raids[name] = r
+		if m := synologyVolume.FindStringSubmatch(name); m != nil {
+			mounts[name] = "/volume" + m[1]
+		}
+	}
+	var descrs []string
+	for _, mount := range mounts {
+		descrs = append(descrs, mount)
+	}
+	volumes, err := hrStorageVolumes(t, descrs...)
+	if err != nil {
+		return err
+	}
+	for name, mount := range mounts {
+		if v, ok := volumes[mount]; ok {
+			if verbose {
+				log.Printf("using storage %s on %s for the capacity of RAID %s\n", mount, t.Name, name)
+			}
+			raids[name].Size = v.SizeBytes()
+			raids[name].Used = v.UsedBytes()
+		}
+	}
+	s.raids = raids
+	return nil
+}
+
+// hrStorageVolumes reads the size and usage from hrStorageTable of the storage with the descriptions given,
+// independently of the storage being tracked for the target
+func hrStorageVolumes(t *target.Target, descrs ...string) (map[string]*info.Storage, error) {
+	volumes := make(map[string]*info.Storage)
+	if len(descrs) == 0 {
+		return volumes, nil
+	}
+	wanted := make(map[string]bool)
+	for _, d := range descrs {
+		wanted[d] = true
+	}
+	all, err := walkTableColumn(t, hrStorageDescr)
+	if err != nil {
+		return volumes, err
+	}
+	var oid []string
+	for idx, v := range all {
+		d := pduString(v)
+		if !wanted[d] {
+			continue
+		}
+		volumes[d] = info.NewStorage(d, idx)
+		for _, col := range []string{hrStorageAllocationUnits, hrStorageSize, hrStorageUsed} {
+			oid = append(oid, fmt.Sprintf("%s.%s", col, idx))
+		}
+	}
+	vars, err := get(t, oid)
+	if err != nil {
+		return volumes, err
+	}
+	values := make(map[string]interface{})
+	for _, variable := range vars {
+		values[variable.Name] = variable.Value
+	}
+	for _, v := range volumes {
+		v.Multiplier = gosnmp.ToBigInt(values[fmt.Sprintf("%s.%s", hrStorageAllocationUnits, v.OIDTail)])
+		v.Size = gosnmp.ToBigInt(values[fmt.Sprintf("%s.%s", hrStorageSize, v.OIDTail)])
+		v.Used = gosnmp.ToBigInt(values[fmt.Sprintf("%s.%s", hrStorageUsed, v.OIDTail)])
+	}
+	return volumes, nil
+}
+
+// Metrics returns the system health, disk and RAID metrics
+func (s *synology) Metrics(t *target.Target) (metrics []*info.Metric) {
+	for n, sensor := range s.health {
+		metrics = append(metrics, &info.Metric{
+			Type:        "health/" + n,
+			Description: fmt.Sprintf("%s %s", n, sensor.Type),
+			Unit:        sensor.Unit,
+			Value:       sensor.Value,
+		})
+	}
+	for _, d := range s.disks {
+		prefix := fmt.Sprintf("disks/%s", info.MetricName(d.ID))
+		metrics = append(metrics,
+			&info.Metric{Type: prefix + "/status", Description: fmt.Sprintf("disk %s status", d.ID), Unit: "1", Value: d.Status},
+			&info.Metric{Type: prefix + "/temperature", Description: fmt.Sprintf("disk %s temperature", d.ID), Unit: "Cel", Value: d.Temperature},
+		)
+	}
+	for _, r := range s.raids {
+		prefix := fmt.Sprintf("raid/%s", info.MetricName(r.Name))
+		metrics = append(metrics,
+			&info.Metric{Type: prefix + "/status", Description: fmt.Sprintf("RAID %s status", r.Name), Unit: "1", Value: r.Status},
+			&info.Metric{Type: prefix + "/size", Description: fmt.Sprintf("RAID %s size", r.Name), Unit: "By", Value: r.Size},
+			&info.Metric{Type: prefix + "/used", Description: fmt.Sprintf("RAID %s used", r.Name), Unit: "By", Value: r.Used},
+		)
+	}
+	return
+}
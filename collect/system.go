@@ -0,0 +1,65 @@
+package collect
+
+import (
+	"log"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	sysDescr    = ".1.3.6.1.2.1.1.1.0"
+	sysObjectID = ".1.3.6.1.2.1.1.2.0"
+	sysUpTime   = ".1.3.6.1.2.1.1.3.0"
+	sysContact  = ".1.3.6.1.2.1.1.4.0"
+	sysName     = ".1.3.6.1.2.1.1.5.0"
+	sysLocation = ".1.3.6.1.2.1.1.6.0"
+
+	// sysUpTime is in hundredths of a second in a 32 bit value so wraps after approximately 497 days
+	sysUpTimeWrap = time.Duration(1<<32) * 10 * time.Millisecond
+)
+
+// System collects the identity and uptime of the target from the SNMPv2-MIB system group
+func System(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	vars, err := get(t, []string{sysDescr, sysObjectID, sysUpTime, sysContact, sysName, sysLocation})
+	if err != nil {
+		return err
+	}
+	ts := time.Now().UTC()
+	for _, variable := range vars {
+		switch variable.Name {
+		case sysDescr:
+			t.System.Descr = pduString(variable.Value)
+		case sysObjectID:
+			t.System.ObjectID = pduString(variable.Value)
+		case sysContact:
+			t.System.Contact = pduString(variable.Value)
+		case sysName:
+			t.System.Name = pduString(variable.Value)
+		case sysLocation:
+			t.System.Location = pduString(variable.Value)
+		case sysUpTime:
+			if verbose {
+				log.Printf("processing SNMP response for sysUpTime from %s\n", t.Name)
+			}
+			upTime := time.Duration(gosnmp.ToBigInt(variable.Value).Int64()) * 10 * time.Millisecond
+			if !t.System.Timestamp.IsZero() && upTime < t.System.UpTime {
+				// a lower uptime is a reboot unless the counter has wrapped
+				if t.System.UpTime+ts.Sub(t.System.Timestamp) < sysUpTimeWrap {
+					t.System.Reboots++
+					log.Printf("%s has rebooted, uptime is now %v\n", t.Name, upTime)
+				}
+			}
+			t.System.UpTime = upTime
+			t.System.BootTime = ts.Add(-upTime)
+			t.System.Timestamp = ts
+		}
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package collect
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// UBNT-AirMAX-MIB
+	ubntRadioEntry  = ".1.3.6.1.4.1.41112.1.4.1.1"
+	ubntWlStatEntry = ".1.3.6.1.4.1.41112.1.4.5.1"
+	ubntStaEntry    = ".1.3.6.1.4.1.41112.1.4.7.1"
+	// UBNT-UniFi-MIB
+	unifiRadioEntry = ".1.3.6.1.4.1.41112.1.6.1.1.1"
+	unifiVapEntry   = ".1.3.6.1.4.1.41112.1.6.1.2.1"
+)
+
+// column numbers of the Ubiquiti tables
+const (
+	ubntRadioFreq        = "4"
+	ubntWlStatSsid       = "2"
+	ubntWlStatCcq        = "7"
+	ubntWlStatNoiseFloor = "8"
+	ubntWlStatChanWidth  = "14"
+	ubntWlStatStaCount   = "15"
+	ubntStaName          = "2"
+	ubntStaSignal        = "3"
+	ubntStaNoiseFloor    = "4"
+	ubntStaTxRate        = "11"
+	ubntStaRxRate        = "12"
+	ubntStaTxBytes       = "13"
+	ubntStaRxBytes       = "14"
+	ubntStaConnTime      = "15"
+	ubntStaTxAirtime     = "19"
+	ubntStaRxAirtime     = "20"
+	unifiRadioRadio      = "3"
+	unifiRadioCuTotal    = "6"
+	unifiVapChannel      = "2"
+	unifiVapCcq          = "3"
+	unifiVapEssID        = "4"
+	unifiVapName         = "7"
+	unifiVapNumStations  = "8"
+	unifiVapRadio        = "9"
+)
+
+func init() {
+	target.RegisterExtension("Ubiquiti", newUbiquiti)
+}
+
+// ubiquiti is the extension collecting the radios and stations of Ubiquiti airMAX and UniFi devices.
+// The target's radios and stations are published with the same metrics as the Mikrotik extension.
+type ubiquiti struct {
+	// WirelessClients are the names to give the stations associated to the target, stations not listed are named
+	// by the device name they report or "unknown"
+	WirelessClients []struct {
+		Name string `json:"Name"`
+		MAC  string `json:"MAC"`
+	} `json:"WirelessClientMACs"`
+	wireless *info.Wireless
+}
+
+func newUbiquiti(data json.RawMessage) (target.Extension, error) {
+	u := &ubiquiti{
+		wireless: info.NewWireless(),
+	}
+	err := json.Unmarshal(data, u)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Collect polls the airMAX tables and, where they are not populated, the UniFi tables
+func (u *ubiquiti) Collect(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+
+	u.wireless.Radios = make(map[string]*info.Radio)
+	// stations keyed by MAC OID
+	stations := make(map[string]*info.WirelessClient)
+	err = u.collectAirMAX(t, stations, verbose)
+	if err != nil {
+		return err
+	}
+	if len(u.wireless.Radios) == 0 {
+		if verbose {
+			log.Printf("UBNT-AirMAX-MIB not available on %s, using UBNT-UniFi-MIB\n", t.Name)
+		}
+		err = u.collectUniFi(t, verbose)
+		if err != nil {
+			return err
+		}
+	}
+	u.updateWirelessClients(t, stations, verbose)
+	wirelessTotals(u.wireless)
+	return nil
+}
+
+// Metrics returns the wireless metrics of the target
+func (u *ubiquiti) Metrics(t *target.Target) []*info.Metric {
+	return wirelessMetrics(u.wireless)
+}
+
+// collectAirMAX collects the radios from ubntWlStatTable and the stations associated with them from ubntStaTable
+func (u *ubiquiti) collectAirMAX(t *target.Target, stations map[string]*info.WirelessClient, verbose bool) error {
+	wlStat, err := walkTableColumn(t, ubntWlStatEntry)
+	if err != nil {
+		return err
+	}
+	if len(wlStat) == 0 {
+		return nil
+	}
+	radioTable, err := walkTableColumn(t, ubntRadioEntry)
+	if err != nil {
+		return err
+	}
+	// radios keyed by ubntWlStatIndex
+	radios := make(map[string]*info.Radio)
+	for idx := range wlStat {
+		parts := strings.SplitN(idx, ".", 2)
+		if len(parts) != 2 || parts[0] != ubntWlStatSsid {
+			continue
+		}
+		i := parts[1]
+		// airOS does not relate its wireless statistics to an ifIndex so radios are named by their index
+		radio := info.NewRadio("radio"+i, i)
+		if verbose {
+			log.Printf("processing airMAX radio %s from %s\n", radio.Interface, t.Name)
+		}
+		radio.SSID = pduString(wlStat[ubntWlStatSsid+"."+i])
+		if v, ok := wlStat[ubntWlStatCcq+"."+i]; ok {
+			radio.CCQ = gosnmp.ToBigInt(v)
+		}
+		if v, ok := wlStat[ubntWlStatNoiseFloor+"."+i]; ok {
+			radio.NoiseFloor = gosnmp.ToBigInt(v)
+		}
+		radio.ClientCount = gosnmp.ToBigInt(wlStat[ubntWlStatStaCount+"."+i])
+		radio.Frequency = gosnmp.ToBigInt(radioTable[ubntRadioFreq+"."+i])
+		radio.Channel = fmt.Sprintf("%s/%s", radio.Frequency, gosnmp.ToBigInt(wlStat[ubntWlStatChanWidth+"."+i]))
+		radio.Airtime = big.NewInt(0)
+		radios[i] = radio
+		u.wireless.Radios[radio.Interface] = radio
+	}
+
+	sta, err := walkTableColumn(t, ubntStaEntry)
+	if err != nil {
+		return err
+	}
+	ts := time.Now().UTC()
+	// tenths of a percent of airtime used by each radio's stations
+	airtime := make(map[string]*big.Int)
+	for idx := range sta {
+		// index is column.ubntWlStatIndex.mac(6 parts)
+		parts := strings.Split(idx, ".")
+		if len(parts) != 8 || parts[0] != ubntStaSignal {
+			continue
+		}
+		radio, ok := radios[parts[1]]
+		if !ok {
+			continue
+		}
+		row := strings.Join(parts[1:], ".")
+		macoid := strings.Join(parts[2:], ".")
+		wcl := info.NewWirelessClient(pduString(sta[ubntStaName+"."+row]), oidTailToMAC(macoid))
+		wcl.Interface = radio.Interface
+		wcl.SignalStrength = gosnmp.ToBigInt(sta[ubntStaSignal+"."+row])
+		wcl.SNR = new(big.Int).Sub(wcl.SignalStrength, gosnmp.ToBigInt(sta[ubntStaNoiseFloor+"."+row]))
+		wcl.TxRate = gosnmp.ToBigInt(sta[ubntStaTxRate+"."+row])
+		wcl.RxRate = gosnmp.ToBigInt(sta[ubntStaRxRate+"."+row])
+		wcl.UpTime = time.Duration(gosnmp.ToBigInt(sta[ubntStaConnTime+"."+row]).Int64()) * 10 * time.Millisecond
+		wcl.TxBytes.Update(gosnmp.ToBigInt(sta[ubntStaTxBytes+"."+row]), ts)
+		wcl.RxBytes.Update(gosnmp.ToBigInt(sta[ubntStaRxBytes+"."+row]), ts)
+		stations[macoid] = wcl
+		if airtime[parts[1]] == nil {
+			airtime[parts[1]] = big.NewInt(0)
+		}
+		airtime[parts[1]].Add(airtime[parts[1]], gosnmp.ToBigInt(sta[ubntStaTxAirtime+"."+row]))
+		airtime[parts[1]].Add(airtime[parts[1]], gosnmp.ToBigInt(sta[ubntStaRxAirtime+"."+row]))
+	}
+	for i, a := range airtime {
+		radios[i].Airtime = a.Div(a, big.NewInt(10))
+	}
+	return nil
+}
+
+// collectUniFi collects each virtual access point of a UniFi access point as a radio.
+// UBNT-UniFi-MIB does not have a station table so only the radio metrics are available, and has no noise floor
+// so the radios' NoiseFloor is left nil and not published.
+func (u *ubiquiti) collectUniFi(t *target.Target, verbose bool) error {
+	radioTable, err := walkTableColumn(t, unifiRadioEntry)
+	if err != nil {
+		return err
+	}
+	// channel utilisation keyed by the radio type, e.g. ng or na
+	cu := make(map[string]*big.Int)
+	for idx, v := range radioTable {
+		parts := strings.SplitN(idx, ".", 2)
+		if len(parts) != 2 || parts[0] != unifiRadioRadio {
+			continue
+		}
+		cu[pduString(v)] = gosnmp.ToBigInt(radioTable[unifiRadioCuTotal+"."+parts[1]])
+	}
+	vap, err := walkTableColumn(t, unifiVapEntry)
+	if err != nil {
+		return err
+	}
+	for idx, v := range vap {
+		parts := strings.SplitN(idx, ".", 2)
+		if len(parts) != 2 || parts[0] != unifiVapName {
+			continue
+		}
+		i := parts[1]
+		radio := info.NewRadio(pduString(v), i)
+		if verbose {
+			log.Printf("processing UniFi VAP %s from %s\n", radio.Interface, t.Name)
+		}
+		radio.SSID = pduString(vap[unifiVapEssID+"."+i])
+		radio.Band = pduString(vap[unifiVapRadio+"."+i])
+		radio.ClientCount = gosnmp.ToBigInt(vap[unifiVapNumStations+"."+i])
+		if ccq, ok := vap[unifiVapCcq+"."+i]; ok {
+			radio.CCQ = new(big.Int).Div(gosnmp.ToBigInt(ccq), big.NewInt(10)) // reported in tenths of a percent
+		}
+		channel := gosnmp.ToBigInt(vap[unifiVapChannel+"."+i]).Int64()
+		radio.Channel = strconv.FormatInt(channel, 10)
+		radio.Frequency = big.NewInt(channelFrequency(channel))
+		radio.Airtime = cu[radio.Band]
+		u.wireless.Radios[radio.Interface] = radio
+	}
+	return nil
+}
+
+// updateWirelessClients replaces the clients being monitored with the stations found, naming them from the
+// configuration where the MAC is listed.
+func (u *ubiquiti) updateWirelessClients(t *target.Target, stations map[string]*info.WirelessClient, verbose bool) {
+	names := make(map[string]string)
+	for _, wcl := range u.WirelessClients {
+		macoid, err := macToOidTail(wcl.MAC)
+		if err != nil {
+			log.Printf("wireless client %s of %s ignored: %v\n", wcl.Name, t.Name, err)
+			continue
+		}
+		names[macoid] = wcl.Name
+	}
+	for macoid, wcl := range stations {
+		if name, ok := names[macoid]; ok {
+			wcl.Name = name
+		} else if wcl.Name == "" {
+			wcl.Name = "unknown"
+		}
+		if prev, ok := u.wireless.ClientConnections[macoid]; ok {
+			// keep the byte counters so their rates can be calculated
+			prev.TxBytes.Update(wcl.TxBytes.Value, wcl.TxBytes.Timestamp)
+			prev.RxBytes.Update(wcl.RxBytes.Value, wcl.RxBytes.Timestamp)
+			wcl.TxBytes, wcl.RxBytes = prev.TxBytes, prev.RxBytes
+		} else if verbose {
+			log.Printf("wireless station %s(%s) has associated to %s on %s\n", wcl.Name, wcl.MAC, t.Name, wcl.Interface)
+		}
+		u.wireless.ClientConnections[macoid] = wcl
+	}
+	for macoid, wcl := range u.wireless.ClientConnections {
+		if _, ok := stations[macoid]; !ok {
+			if verbose {
+				log.Printf("wireless station %s(%s) has left %s\n", wcl.Name, wcl.MAC, t.Name)
+			}
+			delete(u.wireless.ClientConnections, macoid)
+		}
+	}
+}
+
+// channelFrequency returns the centre frequency in MHz of a 2.4GHz or 5GHz WiFi channel number
+func channelFrequency(channel int64) int64 {
+	switch {
+	case channel == 14:
+		return 2484
+	case channel > 0 && channel < 14:
+		return 2407 + 5*channel
+	case channel > 14:
+		return 5000 + 5*channel
+	}
+	return 0
+}
@@ -0,0 +1,158 @@
+package collect
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/soniah/gosnmp"
+)
+
+const (
+	// UPS-MIB (RFC 1628) scalars
+	upsBatteryStatus             = ".1.3.6.1.2.1.33.1.2.1.0"
+	upsEstimatedMinutesRemaining = ".1.3.6.1.2.1.33.1.2.3.0"
+	upsEstimatedChargeRemaining  = ".1.3.6.1.2.1.33.1.2.4.0"
+	upsBatteryVoltage            = ".1.3.6.1.2.1.33.1.2.5.0"
+	upsBatteryTemperature        = ".1.3.6.1.2.1.33.1.2.7.0"
+	upsOutputSource              = ".1.3.6.1.2.1.33.1.4.1.0"
+	upsOutputFrequency           = ".1.3.6.1.2.1.33.1.4.2.0"
+	upsInputFrequency            = ".1.3.6.1.2.1.33.1.3.3.1.2"
+	upsInputVoltage              = ".1.3.6.1.2.1.33.1.3.3.1.3"
+	upsOutputVoltage             = ".1.3.6.1.2.1.33.1.4.4.1.2"
+	upsOutputPercentLoad         = ".1.3.6.1.2.1.33.1.4.4.1.5"
+	// upsBatteryVoltage and the frequencies are reported in tenths
+	upsScale = 0.1
+)
+
+func init() {
+	target.RegisterExtension("UPS", target.NoOptions(newUPS))
+}
+
+// ups is the extension collecting the battery, input and output state of a UPS from UPS-MIB
+type ups struct {
+	ups *info.UPS
+}
+
+func newUPS() target.Extension {
+	return &ups{ups: info.NewUPS()}
+}
+
+// Collect polls the UPS-MIB battery and output scalars and the input and output line tables
+func (u *ups) Collect(t *target.Target, verbose bool) error {
+	err := t.Client.Connect()
+	if err != nil {
+		return err
+	}
+	defer t.Client.Conn.Close()
+	vars, err := get(t, []string{upsBatteryStatus, upsEstimatedMinutesRemaining, upsEstimatedChargeRemaining,
+		upsBatteryVoltage, upsBatteryTemperature, upsOutputSource, upsOutputFrequency})
+	if err != nil {
+		return err
+	}
+	p := info.NewUPS()
+	for _, variable := range vars {
+		if variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		if verbose {
+			log.Printf("processing SNMP response for %s from %s\n", variable.Name, t.Name)
+		}
+		v := gosnmp.ToBigInt(variable.Value).Int64()
+		scaled := float64(v) * upsScale
+		switch variable.Name {
+		case upsBatteryStatus:
+			p.BatteryStatus = &v
+		case upsEstimatedMinutesRemaining:
+			p.MinutesRemaining = &v
+		case upsEstimatedChargeRemaining:
+			p.ChargeRemaining = &v
+		case upsBatteryVoltage:
+			p.BatteryVoltage = &scaled
+		case upsBatteryTemperature:
+			p.BatteryTemp = &v
+		case upsOutputSource:
+			p.OutputSource = &v
+		case upsOutputFrequency:
+			p.OutputFrequency = &scaled
+		}
+	}
+	for _, col := range []string{upsInputFrequency, upsInputVoltage, upsOutputVoltage, upsOutputPercentLoad} {
+		values, err := walkTableColumn(t, col)
+		if err != nil {
+			return err
+		}
+		for line, value := range values {
+			if verbose {
+				log.Printf("processing SNMP response for %s.%s from %s\n", col, line, t.Name)
+			}
+			lines := p.Output
+			if col == upsInputFrequency || col == upsInputVoltage {
+				lines = p.Input
+			}
+			l, ok := lines[line]
+			if !ok {
+				l = new(info.UPSLine)
+				lines[line] = l
+			}
+			v := gosnmp.ToBigInt(value).Int64()
+			switch col {
+			case upsInputFrequency:
+				l.Frequency = float64(v) * upsScale
+			case upsInputVoltage, upsOutputVoltage:
+				l.Voltage = v
+			case upsOutputPercentLoad:
+				l.Load = v
+			}
+		}
+	}
+	p.Timestamp = time.Now().UTC()
+	u.ups = p
+	return nil
+}
+
+// Metrics returns the battery, input line and output line metrics of the UPS.
+// The battery and output values the UPS does not report are not published.
+func (u *ups) Metrics(t *target.Target) (metrics []*info.Metric) {
+	p := u.ups
+	if p.Timestamp.IsZero() {
+		return
+	}
+	for _, m := range []struct {
+		typ   string
+		descr string
+		unit  string
+		i     *int64
+		f     *float64
+	}{
+		{typ: "ups/battery/status", descr: "battery status (1 unknown, 2 normal, 3 low, 4 depleted)", unit: "1", i: p.BatteryStatus},
+		{typ: "ups/battery/minutesremaining", descr: "estimated battery minutes remaining", unit: "min", i: p.MinutesRemaining},
+		{typ: "ups/battery/chargeremaining", descr: "estimated battery charge remaining", unit: "%", i: p.ChargeRemaining},
+		{typ: "ups/battery/voltage", descr: "battery voltage", unit: "V", f: p.BatteryVoltage},
+		{typ: "ups/battery/temperature", descr: "battery temperature", unit: "Cel", i: p.BatteryTemp},
+		{typ: "ups/output/source", descr: "output source (1 other, 2 none, 3 normal, 4 bypass, 5 battery, 6 booster, 7 reducer)", unit: "1", i: p.OutputSource},
+		{typ: "ups/output/frequency", descr: "output frequency", unit: "Hz", f: p.OutputFrequency},
+	} {
+		switch {
+		case m.i != nil:
+			metrics = append(metrics, &info.Metric{Type: m.typ, Description: m.descr, Unit: m.unit, Value: *m.i})
+		case m.f != nil:
+			metrics = append(metrics, &info.Metric{Type: m.typ, Description: m.descr, Unit: m.unit, Value: *m.f})
+		}
+	}
+	for line, l := range p.Input {
+		metrics = append(metrics,
+			&info.Metric{Type: fmt.Sprintf("ups/input/%s/voltage", line), Description: fmt.Sprintf("input line %s voltage", line), Unit: "V", Value: l.Voltage},
+			&info.Metric{Type: fmt.Sprintf("ups/input/%s/frequency", line), Description: fmt.Sprintf("input line %s frequency", line), Unit: "Hz", Value: l.Frequency},
+		)
+	}
+	for line, l := range p.Output {
+		metrics = append(metrics,
+			&info.Metric{Type: fmt.Sprintf("ups/output/%s/voltage", line), Description: fmt.Sprintf("output line %s voltage", line), Unit: "V", Value: l.Voltage},
+			&info.Metric{Type: fmt.Sprintf("ups/output/%s/load", line), Description: fmt.Sprintf("output line %s load", line), Unit: "%", Value: l.Load},
+		)
+	}
+	return
+}
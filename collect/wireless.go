@@ -0,0 +1,75 @@
+package collect
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+)
+
+// wirelessTotals sets the client count across all radios and the average CCQ of the radios with clients.
+// Radios that do not report CCQ, such as CAPsMAN radios, are not included in the average.
+func wirelessTotals(w *info.Wireless) {
+	w.ClientCount = big.NewInt(0)
+	ccq := big.NewInt(0)
+	var n int64
+	for _, radio := range w.Radios {
+		w.ClientCount.Add(w.ClientCount, radio.ClientCount)
+		if radio.ClientCount.Sign() > 0 && radio.CCQ != nil {
+			ccq.Add(ccq, radio.CCQ)
+			n++
+		}
+	}
+	if n > 0 {
+		ccq.Div(ccq, big.NewInt(n))
+	}
+	w.CCQ = ccq
+}
+
+// wirelessMetrics returns the metrics of the radios and wireless clients.
+// The metric types are the same whichever vendor's extension collected them so they can be compared.
+func wirelessMetrics(w *info.Wireless) (metrics []*info.Metric) {
+	if w == nil {
+		return
+	}
+	metrics = append(metrics,
+		&info.Metric{Type: "wireless/clientcount", Description: "wireless client count", Unit: "1", Value: w.ClientCount.Int64()},
+		&info.Metric{Type: "wireless/ccq", Description: "wireless overall CCQ", Unit: "%", Value: w.CCQ.Int64()},
+	)
+	for _, radio := range w.Radios {
+		prefix := fmt.Sprintf("wireless/radios/%s", info.MetricName(radio.Interface))
+		descr := fmt.Sprintf("wireless radio %s", radio.Interface)
+		metrics = append(metrics,
+			&info.Metric{Type: prefix + "/clientcount", Description: descr + " client count", Unit: "1", Value: radio.ClientCount.Int64()},
+			&info.Metric{Type: prefix + "/frequency", Description: descr + " frequency", Unit: "MHz", Value: radio.Frequency.Int64()},
+		)
+		if radio.CCQ != nil {
+			metrics = append(metrics,
+				&info.Metric{Type: prefix + "/ccq", Description: descr + " CCQ", Unit: "%", Value: radio.CCQ.Int64()})
+		}
+		if radio.NoiseFloor != nil {
+			metrics = append(metrics,
+				&info.Metric{Type: prefix + "/noisefloor", Description: descr + " noise floor", Unit: "dBm", Value: radio.NoiseFloor.Int64()})
+		}
+		if radio.Airtime != nil {
+			metrics = append(metrics,
+				&info.Metric{Type: prefix + "/airtime", Description: descr + " airtime", Unit: "%", Value: radio.Airtime.Int64()})
+		}
+	}
+	for _, wcl := range w.ClientConnections {
+		mac := strings.ReplaceAll(wcl.MAC, ":", "")
+		prefix := fmt.Sprintf("wireless/clients/%s/%s", wcl.Name, mac)
+		descr := fmt.Sprintf("wireless client %s(%s)", wcl.Name, mac)
+		metrics = append(metrics,
+			&info.Metric{Type: prefix + "/signalstrength", Description: descr + " signal strength", Unit: "dBm", Value: wcl.SignalStrength.Int64()},
+			&info.Metric{Type: prefix + "/snr", Description: descr + " signal to noise ratio", Unit: "dB", Value: wcl.SNR.Int64()},
+			&info.Metric{Type: prefix + "/txrate", Description: descr + " Tx link rate", Unit: "bit/s", Value: wcl.TxRate.Int64()},
+			&info.Metric{Type: prefix + "/rxrate", Description: descr + " Rx link rate", Unit: "bit/s", Value: wcl.RxRate.Int64()},
+			&info.Metric{Type: prefix + "/uptime", Description: descr + " uptime", Unit: "s", Value: int64(wcl.UpTime.Seconds())},
+			&info.Metric{Type: prefix + "/txbytes", Description: descr + " Tx bytes", Unit: "By/s", Value: wcl.TxBytes.Rate()},
+			&info.Metric{Type: prefix + "/rxbytes", Description: descr + " Rx bytes", Unit: "By/s", Value: wcl.RxBytes.Rate()},
+		)
+	}
+	return
+}
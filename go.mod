@@ -6,6 +6,7 @@ require (
 	cloud.google.com/go v0.68.0
 	github.com/golang/protobuf v1.4.2
 	github.com/soniah/gosnmp v1.27.0
+	golang.org/x/net v0.0.0-20200927032502-5d4f70055728
 	google.golang.org/api v0.33.0
 	google.golang.org/genproto v0.0.0-20201013134114-7f9ee70cb474
 	google.golang.org/grpc v1.32.0
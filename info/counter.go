@@ -0,0 +1,43 @@
+package info
+
+import (
+	"math/big"
+	"time"
+)
+
+// Counter tracks an SNMP counter and its change since the previous poll
+type Counter struct {
+	Value     *big.Int
+	Delta     *big.Int
+	Interval  time.Duration
+	Timestamp time.Time
+}
+
+func NewCounter() *Counter {
+	return &Counter{
+		Value: big.NewInt(0),
+		Delta: big.NewInt(0),
+	}
+}
+
+// Update records the counter value polled at ts.
+// There is no delta for the first value or if the counter has gone backwards due to a reset or wrap.
+func (c *Counter) Update(v *big.Int, ts time.Time) {
+	if c.Timestamp.IsZero() || v.Cmp(c.Value) < 0 {
+		c.Delta.SetInt64(0)
+		c.Interval = 0
+	} else {
+		c.Delta.Sub(v, c.Value)
+		c.Interval = ts.Sub(c.Timestamp)
+	}
+	c.Value = v
+	c.Timestamp = ts
+}
+
+// Rate returns the change in the counter per second
+func (c *Counter) Rate() float64 {
+	if c.Interval.Nanoseconds() == 0 {
+		return 0
+	}
+	return float64(c.Delta.Uint64()) / c.Interval.Seconds()
+}
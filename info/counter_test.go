@@ -0,0 +1,59 @@
+package info
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestCounterUpdate(t *testing.T) {
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	type poll struct {
+		value     int64
+		after     time.Duration // since the previous poll
+		wantDelta int64
+		wantRate  float64
+	}
+	var tests = []struct {
+		name  string
+		polls []poll
+	}{
+		{"first poll has no delta", []poll{
+			{1000, 0, 0, 0},
+		}},
+		{"increasing", []poll{
+			{1000, 0, 0, 0},
+			{1600, 60 * time.Second, 600, 10},
+			{1600, 60 * time.Second, 0, 0},
+		}},
+		{"reset has no delta", []poll{
+			{1000, 0, 0, 0},
+			{400, 60 * time.Second, 0, 0},
+			{1000, 60 * time.Second, 600, 10},
+		}},
+		{"32 bit wrap is treated as a reset", []poll{
+			{4294967000, 0, 0, 0},
+			{200, 30 * time.Second, 0, 0},
+			{500, 30 * time.Second, 300, 10},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewCounter()
+			ts := start
+			for i, p := range test.polls {
+				ts = ts.Add(p.after)
+				c.Update(big.NewInt(p.value), ts)
+				if c.Delta.Int64() != p.wantDelta {
+					t.Errorf("poll %d delta = %d, want %d", i, c.Delta.Int64(), p.wantDelta)
+				}
+				if c.Rate() != p.wantRate {
+					t.Errorf("poll %d rate = %v, want %v", i, c.Rate(), p.wantRate)
+				}
+				if c.Value.Int64() != p.value {
+					t.Errorf("poll %d value = %d, want %d", i, c.Value.Int64(), p.value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package info
+
+import (
+	"math/big"
+	"time"
+)
+
+// Load holds the 1, 5 and 15 minute system load averages
+type Load struct {
+	One       float64
+	Five      float64
+	Fifteen   float64
+	Timestamp time.Time
+}
+
+// CPURaw holds the system wide CPU tick counters, keyed by the type of CPU time, and their change since the last poll
+type CPURaw struct {
+	Ticks     map[string]*big.Int
+	Deltas    map[string]*big.Int
+	Timestamp time.Time
+}
+
+func NewCPURaw() *CPURaw {
+	return &CPURaw{
+		Ticks:  make(map[string]*big.Int),
+		Deltas: make(map[string]*big.Int),
+	}
+}
+
+// Update records the latest tick counter value for the type of CPU time.
+// The delta is zero on the first poll or if the counter has been reset.
+func (c *CPURaw) Update(name string, v *big.Int) {
+	d := new(big.Int)
+	if prev, ok := c.Ticks[name]; ok && v.Cmp(prev) >= 0 {
+		d.Sub(v, prev)
+	}
+	c.Ticks[name] = v
+	c.Deltas[name] = d
+}
+
+// Usage returns the percentage of CPU time spent on the named type of CPU time since the last poll
+// out of the total of the types given.
+func (c *CPURaw) Usage(name string, total ...string) float64 {
+	var sum uint64
+	for _, n := range total {
+		if d, ok := c.Deltas[n]; ok {
+			sum += d.Uint64()
+		}
+	}
+	d, ok := c.Deltas[name]
+	if !ok || sum == 0 {
+		return 0
+	}
+	return (float64(d.Uint64()) / float64(sum)) * 100
+}
+
+// CPUUtilisation holds the busy percentage of a CPU averaged over the last one and five minutes
+type CPUUtilisation struct {
+	Name       string
+	OneMinute  int64
+	FiveMinute int64
+}
@@ -15,6 +15,7 @@ type Iface struct {
 	Speed        *big.Int
 	Delta        time.Duration
 	Timestamp    time.Time
+	Optical      *Optical // nil unless the interface has an optical transceiver reporting DOM values
 }
 
 func NewIface(descr, oidTail string) *Iface {
@@ -62,6 +63,7 @@ func (i *Iface) OutRate() float64 {
 type Storage struct {
 	Description string
 	OIDTail     string
+	Type        string // hrStorageType name, e.g. FixedDisk
 	Size        *big.Int
 	Used        *big.Int
 	Multiplier  *big.Int
@@ -92,22 +94,68 @@ func (s *Storage) UsedBytes() int64 {
 }
 
 type Wireless struct {
-	ClientCount       *big.Int
-	CCQ               *big.Int
+	ClientCount       *big.Int // total across all radios
+	CCQ               *big.Int // average across all radios with clients
+	Radios            map[string]*Radio
 	ClientConnections map[string]*WirelessClient
 }
 
+// Radio is a wireless interface, either local or a CAPsMAN managed remote CAP
+type Radio struct {
+	Interface   string
+	OIDTail     string
+	CAPsMAN     bool
+	SSID        string
+	Band        string
+	Channel     string
+	State       string
+	ClientCount *big.Int
+	CCQ         *big.Int // nil where the radio does not report it
+	Frequency   *big.Int // MHz
+	NoiseFloor  *big.Int // dBm, nil where the radio does not report it
+	Airtime     *big.Int // percentage of time the channel is in use, nil where the vendor does not report it
+}
+
+func NewRadio(iface, oidTail string) *Radio {
+	return &Radio{
+		Interface:   iface,
+		OIDTail:     oidTail,
+		ClientCount: big.NewInt(0),
+		Frequency:   big.NewInt(0),
+	}
+}
+
 type WirelessClient struct {
 	Name           string
 	MAC            string
+	Interface      string
 	SNR            *big.Int
 	SignalStrength *big.Int
+	TxRate         *big.Int // bits per second
+	RxRate         *big.Int // bits per second
+	UpTime         time.Duration
+	TxBytes        *Counter
+	RxBytes        *Counter
+}
+
+func NewWirelessClient(name, mac string) *WirelessClient {
+	return &WirelessClient{
+		Name:           name,
+		MAC:            mac,
+		SNR:            big.NewInt(0),
+		SignalStrength: big.NewInt(0),
+		TxRate:         big.NewInt(0),
+		RxRate:         big.NewInt(0),
+		TxBytes:        NewCounter(),
+		RxBytes:        NewCounter(),
+	}
 }
 
 func NewWireless() *Wireless {
 	return &Wireless{
 		ClientCount:       big.NewInt(0),
 		CCQ:               big.NewInt(0),
+		Radios:            make(map[string]*Radio),
 		ClientConnections: make(map[string]*WirelessClient),
 	}
 }
@@ -0,0 +1,57 @@
+package info
+
+import "time"
+
+// Host is a MAC address seen by a target in its ARP or bridge forwarding tables
+type Host struct {
+	MAC       string
+	IPs       []string // from the ARP table
+	Port      string   // the interface the MAC was learnt on from the bridge forwarding table
+	VLAN      string   // the dot1q filtering database the MAC was learnt in
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// InventoryEvent records a MAC address appearing on or disappearing from a target
+type InventoryEvent struct {
+	MAC    string
+	Port   string
+	Joined bool // false when the MAC has disappeared
+	Time   time.Time
+}
+
+// Inventory holds the hosts seen by a target keyed by MAC and the events of the last poll
+type Inventory struct {
+	Hosts     map[string]*Host
+	Events    []*InventoryEvent
+	Timestamp time.Time
+}
+
+func NewInventory() *Inventory {
+	return &Inventory{
+		Hosts: make(map[string]*Host),
+	}
+}
+
+// PortMACs returns the number of MAC addresses learnt on each port
+func (inv *Inventory) PortMACs() map[string]int64 {
+	n := make(map[string]int64)
+	for _, h := range inv.Hosts {
+		if h.Port != "" {
+			n[h.Port]++
+		}
+	}
+	return n
+}
+
+// EventCounts returns the number of MAC addresses that appeared and disappeared in the last poll
+func (inv *Inventory) EventCounts() (joined, left int64) {
+	for _, e := range inv.Events {
+		if e.Joined {
+			joined++
+		} else {
+			left++
+		}
+	}
+	return
+}
@@ -0,0 +1,28 @@
+package info
+
+import "time"
+
+// Neighbour is a device seen by LLDP on a local port of a target
+type Neighbour struct {
+	LocalPort           string
+	ChassisID           string
+	PortID              string
+	PortDescription     string
+	SystemName          string
+	ManagementAddresses []string
+}
+
+// LLDP holds the neighbours of a target discovered by LLDP
+type LLDP struct {
+	Neighbours []*Neighbour // ordered by local port
+	Timestamp  time.Time
+}
+
+// PortNeighbours returns the number of neighbours seen on each local port
+func (l *LLDP) PortNeighbours() map[string]int64 {
+	n := make(map[string]int64)
+	for _, nbr := range l.Neighbours {
+		n[nbr.LocalPort]++
+	}
+	return n
+}
@@ -0,0 +1,16 @@
+package info
+
+import "time"
+
+// LTEModem holds the signal quality and serving cell of an LTE modem interface
+type LTEModem struct {
+	Interface        string
+	OIDTail          string
+	RSSI             int64 // dBm
+	RSRP             int64 // dBm
+	RSRQ             int64 // dB
+	SINR             int64 // dB
+	CellID           string
+	AccessTechnology string
+	Timestamp        time.Time
+}
@@ -0,0 +1,53 @@
+package info
+
+import (
+	"math/big"
+	"time"
+)
+
+// Memory holds the physical memory and swap of a target in bytes
+type Memory struct {
+	Total         *big.Int
+	Available     *big.Int
+	Buffers       *big.Int
+	Cached        *big.Int
+	SwapTotal     *big.Int
+	SwapAvailable *big.Int
+	Timestamp     time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		Total:         big.NewInt(0),
+		Available:     big.NewInt(0),
+		Buffers:       big.NewInt(0),
+		Cached:        big.NewInt(0),
+		SwapTotal:     big.NewInt(0),
+		SwapAvailable: big.NewInt(0),
+	}
+}
+
+// Used returns the memory in use excluding buffers and cache
+func (m *Memory) Used() int64 {
+	u := m.Total.Int64() - m.Available.Int64() - m.Buffers.Int64() - m.Cached.Int64()
+	if u < 0 {
+		return 0
+	}
+	return u
+}
+
+// SwapUsed returns the swap in use
+func (m *Memory) SwapUsed() int64 {
+	u := m.SwapTotal.Int64() - m.SwapAvailable.Int64()
+	if u < 0 {
+		return 0
+	}
+	return u
+}
+
+// MemoryPool holds the usage of a named pool of memory in bytes, such as the processor or I/O pool of a router
+type MemoryPool struct {
+	Name string
+	Used *big.Int
+	Free *big.Int
+}
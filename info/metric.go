@@ -0,0 +1,28 @@
+package info
+
+import (
+	"regexp"
+	"strings"
+)
+
+// metricNameInvalid matches the characters that cannot be used in a metric type
+var metricNameInvalid = regexp.MustCompile(`[^A-Za-z0-9_.\-]`)
+
+// Metric is a value to publish for a target along with the details needed to describe it
+type Metric struct {
+	Type        string // relative to the target's metric type prefix, e.g. wireless/ccq
+	Description string
+	Unit        string
+	Labels      map[string]string
+	Value       interface{} // int64 or float64
+}
+
+// IfaceName makes an interface description into the name the interface's metrics are published under
+func IfaceName(descr string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(descr, " ", "_"), "/", "")
+}
+
+// MetricName makes a description safe for use within a metric type
+func MetricName(descr string) string {
+	return metricNameInvalid.ReplaceAllString(strings.ReplaceAll(strings.ReplaceAll(descr, " ", "_"), "/", ""), "")
+}
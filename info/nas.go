@@ -0,0 +1,34 @@
+package info
+
+import "math/big"
+
+// Disk is a physical disk of a NAS
+type Disk struct {
+	ID          string
+	Model       string
+	Type        string // e.g. SATA or SSD
+	Status      int64  // vendor status code, 1 is normal
+	Temperature int64  // Cel
+}
+
+// RAID is a storage pool or volume of a NAS and the capacity of the volume it holds
+type RAID struct {
+	Name   string
+	Status int64 // vendor status code, 1 is normal
+	Size   int64 // bytes
+	Used   int64 // bytes
+}
+
+// NewRAID returns a RAID with its capacity calculated from the free and total sizes the NAS reports
+func NewRAID(name string, status int64, free, total *big.Int) *RAID {
+	used := new(big.Int).Sub(total, free)
+	if used.Sign() < 0 {
+		used.SetInt64(0)
+	}
+	return &RAID{
+		Name:   name,
+		Status: status,
+		Size:   total.Int64(),
+		Used:   used.Int64(),
+	}
+}
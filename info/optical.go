@@ -0,0 +1,14 @@
+package info
+
+import "time"
+
+// Optical holds the digital optical monitoring (DOM) values of an SFP transceiver.
+// Values the transceiver does not report are nil.
+type Optical struct {
+	RxPower     *float64 // dBm
+	TxPower     *float64 // dBm
+	Bias        *float64 // laser bias current in mA
+	Temperature *float64 // Cel
+	Voltage     *float64 // V
+	Timestamp   time.Time
+}
@@ -0,0 +1,26 @@
+package info
+
+import "time"
+
+// Ping holds the results of the ICMP echo requests sent to a target in a collection cycle
+type Ping struct {
+	Sent      int64
+	Received  int64
+	Min       time.Duration
+	Avg       time.Duration
+	Max       time.Duration
+	Timestamp time.Time
+}
+
+// Reachable reports if any echo replies were received
+func (p *Ping) Reachable() bool {
+	return p.Received > 0
+}
+
+// Loss returns the percentage of echo requests without a reply
+func (p *Ping) Loss() float64 {
+	if p.Sent == 0 {
+		return 0
+	}
+	return float64(p.Sent-p.Received) / float64(p.Sent) * 100
+}
@@ -0,0 +1,29 @@
+package info
+
+import "time"
+
+// Poll is the outcome of the latest run of a collector against a target
+type Poll struct {
+	Success     bool
+	Duration    time.Duration
+	Varbinds    int64 // variables returned by the target
+	Timeouts    int64 // requests the target did not answer
+	LastSuccess time.Time
+	Timestamp   time.Time
+}
+
+// Start resets the counts ready for a new run of the collector
+func (p *Poll) Start() {
+	p.Varbinds = 0
+	p.Timeouts = 0
+}
+
+// Finish records the outcome of the run of the collector that began at start
+func (p *Poll) Finish(start time.Time, err error) {
+	p.Timestamp = time.Now().UTC()
+	p.Duration = p.Timestamp.Sub(start)
+	p.Success = err == nil
+	if p.Success {
+		p.LastSuccess = p.Timestamp
+	}
+}
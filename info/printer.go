@@ -0,0 +1,24 @@
+package info
+
+import "time"
+
+// PrinterSupply is a consumable or waste receptacle of a printer's marker such as a toner cartridge or drum
+type PrinterSupply struct {
+	Description string
+	Type        string
+	Level       float64 // percentage of the maximum capacity remaining, or filled for waste receptacles
+}
+
+// Printer holds the supplies and the life time page count of each marker of a printer
+type Printer struct {
+	Supplies  map[string]*PrinterSupply // prtMarkerSuppliesTable index : PrinterSupply
+	Pages     map[string]int64          // prtMarkerTable index : prtMarkerLifeCount
+	Timestamp time.Time
+}
+
+func NewPrinter() *Printer {
+	return &Printer{
+		Supplies: make(map[string]*PrinterSupply),
+		Pages:    make(map[string]int64),
+	}
+}
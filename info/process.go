@@ -0,0 +1,43 @@
+package info
+
+import "time"
+
+// ProcessGroup is the processes running on a host whose name matches the pattern configured for the group
+type ProcessGroup struct {
+	Name      string
+	Count     int64
+	CPU       map[string]*Counter // centi-seconds of CPU time used by each process in the group keyed by hrSWRunIndex
+	Memory    int64               // total real memory allocated to the processes in the group in bytes
+	Timestamp time.Time
+}
+
+func NewProcessGroup(name string) *ProcessGroup {
+	return &ProcessGroup{
+		Name: name,
+		CPU:  make(map[string]*Counter),
+	}
+}
+
+// Missing reports if none of the processes of the group are running
+func (g *ProcessGroup) Missing() bool {
+	return g.Count == 0
+}
+
+// CPUUsage returns the CPU time used by the group since the last poll as a percentage of a single CPU.
+// It is the sum of the CPU time used by each process of the group running at both polls, so processes starting
+// or ending do not distort it.
+func (g *ProcessGroup) CPUUsage() float64 {
+	var sum uint64
+	var interval time.Duration
+	for _, c := range g.CPU {
+		sum += c.Delta.Uint64()
+		if c.Interval > interval {
+			interval = c.Interval
+		}
+	}
+	if interval == 0 {
+		return 0
+	}
+	// centi-seconds per second is the percentage
+	return float64(sum) / interval.Seconds()
+}
@@ -0,0 +1,42 @@
+package info
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestProcessGroupCPUUsage(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var tests = []struct {
+		name  string
+		polls []map[string]int64 // hrSWRunIndex : hrSWRunPerfCPU at each poll, 10s apart
+		want  float64
+	}{
+		{"first poll", []map[string]int64{{"1": 500}}, 0},
+		{"steady", []map[string]int64{{"1": 500, "2": 100}, {"1": 600, "2": 150}}, 15},
+		{"process ended", []map[string]int64{{"1": 500, "2": 10000}, {"1": 600}}, 10},
+		{"process started", []map[string]int64{{"1": 500}, {"1": 600, "2": 10000}}, 10},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewProcessGroup("test")
+			for i, poll := range test.polls {
+				ts := start.Add(time.Duration(i) * 10 * time.Second)
+				cpu := make(map[string]*Counter)
+				for idx, v := range poll {
+					c, ok := g.CPU[idx]
+					if !ok {
+						c = NewCounter()
+					}
+					c.Update(big.NewInt(v), ts)
+					cpu[idx] = c
+				}
+				g.CPU = cpu
+			}
+			if got := g.CPUUsage(); got != test.want {
+				t.Errorf("CPUUsage() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package info
+
+import "time"
+
+// Protocols holds the IP, TCP and UDP stack counters of a host, keyed by the name they are published under,
+// along with the number of established TCP connections
+type Protocols struct {
+	Counters       map[string]*Counter
+	TCPEstablished int64
+	Timestamp      time.Time
+}
+
+func NewProtocols() *Protocols {
+	return &Protocols{
+		Counters: make(map[string]*Counter),
+	}
+}
+
+// Counter returns the named counter, creating it if it does not yet exist
+func (p *Protocols) Counter(name string) *Counter {
+	c, ok := p.Counters[name]
+	if !ok {
+		c = NewCounter()
+		p.Counters[name] = c
+	}
+	return c
+}
@@ -0,0 +1,28 @@
+package info
+
+// Queue holds the counters of a Mikrotik simple queue or queue tree entry keyed by counter name
+type Queue struct {
+	Name     string
+	Kind     string // simple or tree
+	OIDTail  string
+	Counters map[string]*Counter
+}
+
+func NewQueue(name, kind, oidTail string) *Queue {
+	return &Queue{
+		Name:     name,
+		Kind:     kind,
+		OIDTail:  oidTail,
+		Counters: make(map[string]*Counter),
+	}
+}
+
+// Counter returns the named counter of the queue, creating it if it does not yet exist
+func (q *Queue) Counter(name string) *Counter {
+	c, ok := q.Counters[name]
+	if !ok {
+		c = NewCounter()
+		q.Counters[name] = c
+	}
+	return c
+}
@@ -0,0 +1,32 @@
+package info
+
+import "time"
+
+// BGPPeer is the session state of a BGP peer, keyed by its remote address
+type BGPPeer struct {
+	Address          string
+	RemoteAS         int64
+	State            int64 // 1 idle, 2 connect, 3 active, 4 opensent, 5 openconfirm, 6 established
+	EstablishedTime  int64 // seconds the session has been, or was last, established
+	PrefixesReceived int64 // -1 where the target does not report it
+	InUpdates        *Counter
+	OutUpdates       *Counter
+	Timestamp        time.Time
+}
+
+func NewBGPPeer(addr string) *BGPPeer {
+	return &BGPPeer{
+		Address:          addr,
+		PrefixesReceived: -1,
+		InUpdates:        NewCounter(),
+		OutUpdates:       NewCounter(),
+	}
+}
+
+// OSPFNeighbour is the adjacency state of an OSPF neighbour
+type OSPFNeighbour struct {
+	Address   string
+	RouterID  string
+	State     int64 // 1 down, 2 attempt, 3 init, 4 twoWay, 5 exchangeStart, 6 exchange, 7 loading, 8 full
+	Timestamp time.Time
+}
@@ -0,0 +1,12 @@
+package info
+
+import "time"
+
+// Sensor is a hardware sensor reading such as a temperature, voltage, fan speed or power supply state
+type Sensor struct {
+	Name      string
+	Type      string // temperature, voltage, current, power, fan or state
+	Unit      string // unit of the Value, in the UCUM form used by Cloud Monitoring
+	Value     float64
+	Timestamp time.Time
+}
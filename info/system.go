@@ -0,0 +1,16 @@
+package info
+
+import "time"
+
+// System holds the SNMPv2-MIB system group identity and uptime of a target
+type System struct {
+	Descr     string
+	ObjectID  string
+	Name      string
+	Location  string
+	Contact   string
+	UpTime    time.Duration
+	BootTime  time.Time // when the target last booted, from sysUpTime so it is wrong once sysUpTime has wrapped
+	Reboots   int64     // number of reboots observed since this poller started, it is not persisted
+	Timestamp time.Time
+}
@@ -0,0 +1,32 @@
+package info
+
+import "time"
+
+// UPS holds the battery, input and output state of an uninterruptible power supply.
+// Values the UPS does not report are nil.
+type UPS struct {
+	BatteryStatus    *int64   // 1 unknown, 2 normal, 3 low, 4 depleted
+	MinutesRemaining *int64   // estimated minutes of battery run time
+	ChargeRemaining  *int64   // estimated percentage of battery charge
+	BatteryVoltage   *float64 // V
+	BatteryTemp      *int64   // Cel
+	OutputSource     *int64   // 1 other, 2 none, 3 normal, 4 bypass, 5 battery, 6 booster, 7 reducer
+	OutputFrequency  *float64 // Hz
+	Input            map[string]*UPSLine
+	Output           map[string]*UPSLine
+	Timestamp        time.Time
+}
+
+// UPSLine is an input or output line of a UPS, keyed by its line index
+type UPSLine struct {
+	Voltage   int64   // V
+	Frequency float64 // Hz, input lines only
+	Load      int64   // percentage of rated capacity, output lines only
+}
+
+func NewUPS() *UPS {
+	return &UPS{
+		Input:  make(map[string]*UPSLine),
+		Output: make(map[string]*UPSLine),
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"sync"
@@ -10,10 +11,12 @@ import (
 	"github.com/jcmturner/snmpgcpmonitoring/collect"
 	"github.com/jcmturner/snmpgcpmonitoring/store"
 	"github.com/jcmturner/snmpgcpmonitoring/target"
+	"github.com/jcmturner/snmpgcpmonitoring/topology"
 )
 
 func main() {
 	erase := flag.Bool("erase", false, "erase all historical data and metric descriptors")
+	topo := flag.String("topology", "", "poll the LLDP neighbours of all targets once and print the topology as json or dot")
 	flag.Parse()
 
 	var verbose bool
@@ -25,6 +28,17 @@ func main() {
 	if p == "" {
 		log.Fatalln("TARGETS_CONF environment variable not set")
 	}
+	if *topo != "" {
+		ts, err := target.Load(p)
+		if err != nil {
+			log.Fatalf("error loading targets configuration: %v", err)
+		}
+		err = exportTopology(ts, *topo, verbose)
+		if err != nil {
+			log.Fatalf("error exporting topology: %v", err)
+		}
+		os.Exit(0)
+	}
 	client, err := store.Initialise()
 	if err != nil {
 		log.Fatalf("error initialising metrics client: %v", err)
@@ -54,3 +68,35 @@ func run(ts []*target.Target, client *monitoring.MetricClient, verbose bool) {
 	}
 	wg.Wait()
 }
+
+// exportTopology polls the identity and LLDP neighbours of all the targets and writes the merged topology to stdout
+func exportTopology(ts []*target.Target, format string, verbose bool) error {
+	if format != "json" && format != "dot" {
+		return fmt.Errorf("unknown topology format %s, must be json or dot", format)
+	}
+	devices := make([]topology.Device, len(ts))
+	var wg sync.WaitGroup
+	wg.Add(len(ts))
+	for i, t := range ts {
+		go func(i int, t *target.Target) {
+			defer wg.Done()
+			devices[i].Name = t.Name
+			err := collect.System(t, verbose)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "system metrics collection from %s error: %v\n", t.Name, err)
+			} else if t.System.Name != "" {
+				devices[i].Name = t.System.Name
+			}
+			devices[i].Neighbours, err = collect.LLDP(t, verbose)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "lldp metrics collection from %s error: %v\n", t.Name, err)
+			}
+		}(i, t)
+	}
+	wg.Wait()
+	top := topology.New(devices)
+	if format == "dot" {
+		return top.DOT(os.Stdout)
+	}
+	return top.JSON(os.Stdout)
+}
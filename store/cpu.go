@@ -0,0 +1,70 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
+)
+
+// cpuRawTotal are the types of CPU time that together make up all CPU time
+var cpuRawTotal = []string{"user", "nice", "system", "idle", "wait"}
+
+// cpuRawMetrics maps the published metric name to the type of CPU time
+var cpuRawMetrics = map[string]string{
+	"user":   "user",
+	"system": "system",
+	"idle":   "idle",
+	"iowait": "wait",
+}
+
+func cpuTimeSeries(t *target.Target, prefix string, now *timestamp.Timestamp) (series []*monitoringpb.TimeSeries) {
+	if len(t.CPU) > 0 {
+		series = append(series, timeSeries(fmt.Sprintf("%s/cpu/average", prefix), nil, now, doubleValue(t.CPUAverage)))
+	}
+	if t.CPURaw != nil && !t.CPURaw.Timestamp.IsZero() {
+		for name, typ := range cpuRawMetrics {
+			series = append(series, timeSeries(fmt.Sprintf("%s/cpu/%s", prefix, name), nil, now,
+				doubleValue(t.CPURaw.Usage(typ, cpuRawTotal...))))
+		}
+	}
+	if t.Load != nil && !t.Load.Timestamp.IsZero() {
+		series = append(series,
+			timeSeries(fmt.Sprintf("%s/load/1min", prefix), nil, now, doubleValue(t.Load.One)),
+			timeSeries(fmt.Sprintf("%s/load/5min", prefix), nil, now, doubleValue(t.Load.Five)),
+			timeSeries(fmt.Sprintf("%s/load/15min", prefix), nil, now, doubleValue(t.Load.Fifteen)),
+		)
+	}
+	return
+}
+
+func cpuDescriptors(t *target.Target, prefix, projectID string) (reqs []*monitoringpb.CreateMetricDescriptorRequest) {
+	if len(t.CPU) > 0 {
+		reqs = append(reqs, gaugeDescriptor(projectID,
+			fmt.Sprintf("%s-cpu-average", t.Name),
+			fmt.Sprintf("%s/cpu/average", prefix),
+			metricpb.MetricDescriptor_DOUBLE, "%",
+			fmt.Sprintf("%s average cpu usage", t.Name)))
+	}
+	if t.CPURaw != nil && !t.CPURaw.Timestamp.IsZero() {
+		for name := range cpuRawMetrics {
+			reqs = append(reqs, gaugeDescriptor(projectID,
+				fmt.Sprintf("%s-cpu-%s", t.Name, name),
+				fmt.Sprintf("%s/cpu/%s", prefix, name),
+				metricpb.MetricDescriptor_DOUBLE, "%",
+				fmt.Sprintf("%s cpu %s time", t.Name, name)))
+		}
+	}
+	if t.Load != nil && !t.Load.Timestamp.IsZero() {
+		for _, period := range []string{"1min", "5min", "15min"} {
+			reqs = append(reqs, gaugeDescriptor(projectID,
+				fmt.Sprintf("%s-load-%s", t.Name, period),
+				fmt.Sprintf("%s/load/%s", prefix, period),
+				metricpb.MetricDescriptor_DOUBLE, "1",
+				fmt.Sprintf("%s %s load average", t.Name, period)))
+		}
+	}
+	return
+}
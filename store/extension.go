@@ -0,0 +1,68 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
+)
+
+// extensionMetrics returns the metrics of all the extensions configured for the target
+func extensionMetrics(t *target.Target) (metrics []*info.Metric) {
+	for _, name := range t.ExtensionNames() {
+		metrics = append(metrics, t.Exts[name].Metrics(t)...)
+	}
+	return
+}
+
+func extensionTimeSeries(t *target.Target, prefix string, now *timestamp.Timestamp) (series []*monitoringpb.TimeSeries) {
+	for _, m := range extensionMetrics(t) {
+		var v *monitoringpb.TypedValue
+		switch value := m.Value.(type) {
+		case int64:
+			v = int64Value(value)
+		case float64:
+			v = doubleValue(value)
+		default:
+			continue
+		}
+		series = append(series, timeSeries(fmt.Sprintf("%s/%s", prefix, m.Type), m.Labels, now, v))
+	}
+	return
+}
+
+func extensionDescriptors(t *target.Target, prefix, projectID string) (reqs []*monitoringpb.CreateMetricDescriptorRequest) {
+	// metrics with labels have a time series for each set of label values but only one descriptor
+	seen := make(map[string]bool)
+	for _, m := range extensionMetrics(t) {
+		if seen[m.Type] {
+			continue
+		}
+		seen[m.Type] = true
+		var valueType metricpb.MetricDescriptor_ValueType
+		switch m.Value.(type) {
+		case int64:
+			valueType = metricpb.MetricDescriptor_INT64
+		case float64:
+			valueType = metricpb.MetricDescriptor_DOUBLE
+		default:
+			continue
+		}
+		var labels []string
+		for l := range m.Labels {
+			labels = append(labels, l)
+		}
+		sort.Strings(labels)
+		reqs = append(reqs, gaugeDescriptor(projectID,
+			fmt.Sprintf("%s-%s", t.Name, strings.ReplaceAll(m.Type, "/", "-")),
+			fmt.Sprintf("%s/%s", prefix, m.Type),
+			valueType, m.Unit,
+			fmt.Sprintf("%s %s", t.Name, m.Description), labels...))
+	}
+	return
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
+)
+
+func memoryTimeSeries(t *target.Target, prefix string, now *timestamp.Timestamp) (series []*monitoringpb.TimeSeries) {
+	if t.Memory == nil || t.Memory.Timestamp.IsZero() {
+		return
+	}
+	m := t.Memory
+	return append(series,
+		timeSeries(fmt.Sprintf("%s/memory/total", prefix), nil, now, int64Value(m.Total.Int64())),
+		timeSeries(fmt.Sprintf("%s/memory/used", prefix), nil, now, int64Value(m.Used())),
+		timeSeries(fmt.Sprintf("%s/memory/available", prefix), nil, now, int64Value(m.Available.Int64())),
+		timeSeries(fmt.Sprintf("%s/memory/cached", prefix), nil, now, int64Value(m.Cached.Int64())),
+		timeSeries(fmt.Sprintf("%s/memory/buffers", prefix), nil, now, int64Value(m.Buffers.Int64())),
+		timeSeries(fmt.Sprintf("%s/swap/total", prefix), nil, now, int64Value(m.SwapTotal.Int64())),
+		timeSeries(fmt.Sprintf("%s/swap/used", prefix), nil, now, int64Value(m.SwapUsed())),
+	)
+}
+
+func memoryDescriptors(t *target.Target, prefix, projectID string) (reqs []*monitoringpb.CreateMetricDescriptorRequest) {
+	if t.Memory == nil || t.Memory.Timestamp.IsZero() {
+		return
+	}
+	for _, m := range []struct {
+		name  string
+		descr string
+	}{
+		{"memory/total", "memory total"},
+		{"memory/used", "memory used"},
+		{"memory/available", "memory available"},
+		{"memory/cached", "memory cached"},
+		{"memory/buffers", "memory buffers"},
+		{"swap/total", "swap total"},
+		{"swap/used", "swap used"},
+	} {
+		reqs = append(reqs, gaugeDescriptor(projectID,
+			fmt.Sprintf("%s-%s", t.Name, strings.ReplaceAll(m.name, "/", "-")),
+			fmt.Sprintf("%s/%s", prefix, m.name),
+			metricpb.MetricDescriptor_INT64, "By",
+			fmt.Sprintf("%s %s", t.Name, m.descr)))
+	}
+	return
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
+)
+
+// started is when the poller started
+var started = time.Now().UTC()
+
+func pollTimeSeries(t *target.Target, prefix string, now *timestamp.Timestamp) (series []*monitoringpb.TimeSeries) {
+	for name, p := range t.Polls {
+		if p.Timestamp.IsZero() {
+			continue
+		}
+		var success int64
+		if p.Success {
+			success = 1
+		}
+		typ := fmt.Sprintf("%s/poll/%s", prefix, info.MetricName(name))
+		series = append(series,
+			timeSeries(typ+"/success", nil, now, int64Value(success)),
+			timeSeries(typ+"/duration", nil, now, doubleValue(p.Duration.Seconds())),
+			timeSeries(typ+"/varbinds", nil, now, int64Value(p.Varbinds)),
+			timeSeries(typ+"/timeouts", nil, now, int64Value(p.Timeouts)),
+		)
+		if !p.LastSuccess.IsZero() {
+			series = append(series, timeSeries(typ+"/lastsuccess", nil, now, int64Value(p.LastSuccess.Unix())))
+		}
+		// collectors that have never succeeded are aged from when the poller started
+		last := p.LastSuccess
+		if last.IsZero() {
+			last = started
+		}
+		series = append(series, timeSeries(typ+"/sincelastsuccess", nil, now,
+			doubleValue(t.CollectTime.Sub(last).Seconds())))
+	}
+	return
+}
+
+func pollDescriptors(t *target.Target, prefix, projectID string) (reqs []*monitoringpb.CreateMetricDescriptorRequest) {
+	for name, p := range t.Polls {
+		if p.Timestamp.IsZero() {
+			continue
+		}
+		descrip := info.MetricName(name)
+		for _, m := range []struct {
+			metric    string
+			valueType metricpb.MetricDescriptor_ValueType
+			unit      string
+			descr     string
+		}{
+			{"success", metricpb.MetricDescriptor_INT64, "1", "poll success, 1 if successful"},
+			{"duration", metricpb.MetricDescriptor_DOUBLE, "s", "poll duration"},
+			{"varbinds", metricpb.MetricDescriptor_INT64, "1", "poll variables returned"},
+			{"timeouts", metricpb.MetricDescriptor_INT64, "1", "poll request timeouts"},
+			{"lastsuccess", metricpb.MetricDescriptor_INT64, "s", "time of the last successful poll in seconds since the epoch"},
+			{"sincelastsuccess", metricpb.MetricDescriptor_DOUBLE, "s", "time since the last successful poll, or the poller starting"},
+		} {
+			reqs = append(reqs, gaugeDescriptor(projectID,
+				fmt.Sprintf("%s-poll-%s-%s", t.Name, descrip, m.metric),
+				fmt.Sprintf("%s/poll/%s/%s", prefix, descrip, m.metric),
+				m.valueType, m.unit,
+				fmt.Sprintf("%s %s %s", t.Name, name, m.descr)))
+		}
+	}
+	return
+}
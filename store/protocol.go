@@ -0,0 +1,42 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
+)
+
+func protocolTimeSeries(t *target.Target, prefix string, now *timestamp.Timestamp) (series []*monitoringpb.TimeSeries) {
+	if t.Protocols == nil || t.Protocols.Timestamp.IsZero() {
+		return
+	}
+	series = append(series, timeSeries(fmt.Sprintf("%s/tcp/established", prefix), nil, now,
+		int64Value(t.Protocols.TCPEstablished)))
+	for name, c := range t.Protocols.Counters {
+		series = append(series, timeSeries(fmt.Sprintf("%s/%s", prefix, name), nil, now, doubleValue(c.Rate())))
+	}
+	return
+}
+
+func protocolDescriptors(t *target.Target, prefix, projectID string) (reqs []*monitoringpb.CreateMetricDescriptorRequest) {
+	if t.Protocols == nil || t.Protocols.Timestamp.IsZero() {
+		return
+	}
+	reqs = append(reqs, gaugeDescriptor(projectID,
+		fmt.Sprintf("%s-tcp-established", t.Name),
+		fmt.Sprintf("%s/tcp/established", prefix),
+		metricpb.MetricDescriptor_INT64, "1",
+		fmt.Sprintf("%s established TCP connections", t.Name)))
+	for name := range t.Protocols.Counters {
+		reqs = append(reqs, gaugeDescriptor(projectID,
+			fmt.Sprintf("%s-%s", t.Name, strings.ReplaceAll(name, "/", "-")),
+			fmt.Sprintf("%s/%s", prefix, name),
+			metricpb.MetricDescriptor_DOUBLE, "1/s",
+			fmt.Sprintf("%s %s rate", t.Name, strings.ReplaceAll(name, "/", " "))))
+	}
+	return
+}
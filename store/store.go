@@ -11,9 +11,11 @@ import (
 
 	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
 	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/jcmturner/snmpgcpmonitoring/info"
 	"github.com/jcmturner/snmpgcpmonitoring/target"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
+	"google.golang.org/genproto/googleapis/api/label"
 	metricpb "google.golang.org/genproto/googleapis/api/metric"
 	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
 	"google.golang.org/grpc"
@@ -24,7 +26,9 @@ import (
 
 const (
 	metricTypePrefix = "custom.googleapis.com"
-	ca_certs         = `-----BEGIN CERTIFICATE-----
+	// maxTimeSeriesPerRequest is the limit Cloud Monitoring places on the time series in one create request
+	maxTimeSeriesPerRequest = 200
+	ca_certs                = `-----BEGIN CERTIFICATE-----
 MIIDujCCAqKgAwIBAgILBAAAAAABD4Ym5g0wDQYJKoZIhvcNAQEFBQAwTDEgMB4G
 A1UECxMXR2xvYmFsU2lnbiBSb290IENBIC0gUjIxEzARBgNVBAoTCkdsb2JhbFNp
 Z24xEzARBgNVBAMTCkdsb2JhbFNpZ24wHhcNMDYxMjE1MDgwMDAwWhcNMjExMjE1
@@ -207,6 +211,10 @@ func Metrics(client *monitoring.MetricClient, t *target.Target, verbose bool) er
 	}
 
 	for strg, info := range t.Storage {
+		if info == nil {
+			// storage not currently present on the target
+			continue
+		}
 		descrip := strings.ReplaceAll(strings.ReplaceAll(strg, " ", "_"), "/", "")
 		typ := fmt.Sprintf("%s/storage/%s/used", prefix, descrip)
 		req.TimeSeries = append(req.TimeSeries, &monitoringpb.TimeSeries{
@@ -249,8 +257,12 @@ func Metrics(client *monitoring.MetricClient, t *target.Target, verbose bool) er
 			log.Printf("adding timeseries data for %s at %v\n", typ, t.CollectTime)
 		}
 	}
-	for iface, info := range t.Ifaces {
-		descrip := strings.ReplaceAll(strings.ReplaceAll(iface, " ", "_"), "/", "")
+	for iface, ifInfo := range t.Ifaces {
+		if ifInfo == nil {
+			// interface not currently present on the target
+			continue
+		}
+		descrip := info.IfaceName(iface)
 		typ := fmt.Sprintf("%s/interface/%s/txrate", prefix, descrip)
 		req.TimeSeries = append(req.TimeSeries, &monitoringpb.TimeSeries{
 			Metric: &metricpb.Metric{
@@ -263,7 +275,7 @@ func Metrics(client *monitoring.MetricClient, t *target.Target, verbose bool) er
 				},
 				Value: &monitoringpb.TypedValue{
 					Value: &monitoringpb.TypedValue_DoubleValue{
-						DoubleValue: info.OutRate(),
+						DoubleValue: ifInfo.OutRate(),
 					},
 				},
 			}},
@@ -283,7 +295,7 @@ func Metrics(client *monitoring.MetricClient, t *target.Target, verbose bool) er
 				},
 				Value: &monitoringpb.TypedValue{
 					Value: &monitoringpb.TypedValue_DoubleValue{
-						DoubleValue: info.InRate(),
+						DoubleValue: ifInfo.InRate(),
 					},
 				},
 			}},
@@ -293,100 +305,95 @@ func Metrics(client *monitoring.MetricClient, t *target.Target, verbose bool) er
 		}
 	}
 
-	if t.Wireless != nil {
-		typ := fmt.Sprintf("%s/wireless/clientcount", prefix)
-		req.TimeSeries = append(req.TimeSeries, &monitoringpb.TimeSeries{
-			Metric: &metricpb.Metric{
-				Type: typ,
-			},
-			Points: []*monitoringpb.Point{{
-				Interval: &monitoringpb.TimeInterval{
-					StartTime: now,
-					EndTime:   now,
-				},
-				Value: &monitoringpb.TypedValue{
-					Value: &monitoringpb.TypedValue_Int64Value{
-						Int64Value: t.Wireless.ClientCount.Int64(),
-					},
-				},
-			}},
-		})
-		if verbose {
-			log.Printf("adding timeseries data for %s at %v\n", typ, t.CollectTime)
+	appendTimeSeries(req, t, verbose, systemTimeSeries(t, prefix, now)...)
+	appendTimeSeries(req, t, verbose, pollTimeSeries(t, prefix, now)...)
+	appendTimeSeries(req, t, verbose, cpuTimeSeries(t, prefix, now)...)
+	appendTimeSeries(req, t, verbose, memoryTimeSeries(t, prefix, now)...)
+	appendTimeSeries(req, t, verbose, protocolTimeSeries(t, prefix, now)...)
+	appendTimeSeries(req, t, verbose, extensionTimeSeries(t, prefix, now)...)
+
+	ctx := context.Background()
+	series := req.TimeSeries
+	for len(series) > 0 {
+		n := maxTimeSeriesPerRequest
+		if len(series) < n {
+			n = len(series)
 		}
-		typ = fmt.Sprintf("%s/wireless/ccq", prefix)
-		req.TimeSeries = append(req.TimeSeries, &monitoringpb.TimeSeries{
-			Metric: &metricpb.Metric{
-				Type: typ,
-			},
-			Points: []*monitoringpb.Point{{
-				Interval: &monitoringpb.TimeInterval{
-					StartTime: now,
-					EndTime:   now,
-				},
-				Value: &monitoringpb.TypedValue{
-					Value: &monitoringpb.TypedValue_Int64Value{
-						Int64Value: t.Wireless.CCQ.Int64(),
-					},
-				},
-			}},
-		})
-		if verbose {
-			log.Printf("adding timeseries data for %s at %v\n", typ, t.CollectTime)
+		req.TimeSeries = series[:n]
+		err = client.CreateTimeSeries(ctx, req)
+		if err != nil {
+			return err
 		}
+		series = series[n:]
+	}
 
-		for _, wcl := range t.Wireless.ClientConnections {
-			mac := strings.ReplaceAll(wcl.MAC, ":", "")
-			typ := fmt.Sprintf("%s/wireless/clients/%s/%s/signalstrength", prefix, wcl.Name, mac)
-			req.TimeSeries = append(req.TimeSeries, &monitoringpb.TimeSeries{
-				Metric: &metricpb.Metric{
-					Type: typ,
-				},
-				Points: []*monitoringpb.Point{{
-					Interval: &monitoringpb.TimeInterval{
-						StartTime: now,
-						EndTime:   now,
-					},
-					Value: &monitoringpb.TypedValue{
-						Value: &monitoringpb.TypedValue_Int64Value{
-							Int64Value: wcl.SignalStrength.Int64(),
-						},
-					},
-				}},
-			})
-			if verbose {
-				log.Printf("adding timeseries data for %s at %v\n", typ, t.CollectTime)
-			}
-			typ = fmt.Sprintf("%s/wireless/clients/%s/%s/snr", prefix, wcl.Name, mac)
-			req.TimeSeries = append(req.TimeSeries, &monitoringpb.TimeSeries{
-				Metric: &metricpb.Metric{
-					Type: typ,
-				},
-				Points: []*monitoringpb.Point{{
-					Interval: &monitoringpb.TimeInterval{
-						StartTime: now,
-						EndTime:   now,
-					},
-					Value: &monitoringpb.TypedValue{
-						Value: &monitoringpb.TypedValue_Int64Value{
-							Int64Value: wcl.SNR.Int64(),
-						},
-					},
-				}},
-			})
-			if verbose {
-				log.Printf("adding timeseries data for %s at %v\n", typ, t.CollectTime)
-			}
+	return nil
+}
+
+// appendTimeSeries adds the time series to the request
+func appendTimeSeries(req *monitoringpb.CreateTimeSeriesRequest, t *target.Target, verbose bool, series ...*monitoringpb.TimeSeries) {
+	for _, ts := range series {
+		req.TimeSeries = append(req.TimeSeries, ts)
+		if verbose {
+			log.Printf("adding timeseries data for %s at %v\n", ts.Metric.Type, t.CollectTime)
 		}
 	}
+}
 
-	ctx := context.Background()
-	err = client.CreateTimeSeries(ctx, req)
-	if err != nil {
-		return err
+// timeSeries returns a time series of a single gauge point for the metric type
+func timeSeries(typ string, labels map[string]string, now *timestamp.Timestamp, value *monitoringpb.TypedValue) *monitoringpb.TimeSeries {
+	return &monitoringpb.TimeSeries{
+		Metric: &metricpb.Metric{
+			Type:   typ,
+			Labels: labels,
+		},
+		Points: []*monitoringpb.Point{{
+			Interval: &monitoringpb.TimeInterval{
+				StartTime: now,
+				EndTime:   now,
+			},
+			Value: value,
+		}},
 	}
+}
 
-	return nil
+func int64Value(v int64) *monitoringpb.TypedValue {
+	return &monitoringpb.TypedValue{
+		Value: &monitoringpb.TypedValue_Int64Value{
+			Int64Value: v,
+		},
+	}
+}
+
+func doubleValue(v float64) *monitoringpb.TypedValue {
+	return &monitoringpb.TypedValue{
+		Value: &monitoringpb.TypedValue_DoubleValue{
+			DoubleValue: v,
+		},
+	}
+}
+
+// gaugeDescriptor returns the request to create a gauge metric descriptor with the string labels provided
+func gaugeDescriptor(projectID, name, typ string, valueType metricpb.MetricDescriptor_ValueType, unit, description string, labels ...string) *monitoringpb.CreateMetricDescriptorRequest {
+	desc := &metricpb.MetricDescriptor{
+		Name:        name,
+		Type:        typ,
+		MetricKind:  metricpb.MetricDescriptor_GAUGE,
+		ValueType:   valueType,
+		Unit:        unit,
+		Description: description,
+		DisplayName: description,
+	}
+	for _, l := range labels {
+		desc.Labels = append(desc.Labels, &label.LabelDescriptor{
+			Key:       l,
+			ValueType: label.LabelDescriptor_STRING,
+		})
+	}
+	return &monitoringpb.CreateMetricDescriptorRequest{
+		Name:             "projects/" + projectID,
+		MetricDescriptor: desc,
+	}
 }
 
 func metricTypeTargetPrefix(t *target.Target) string {
@@ -416,6 +423,9 @@ func getMetricDescriptorNames(t *target.Target, projectID string) (reqs []*monit
 	}
 
 	for _, info := range t.Storage {
+		if info == nil {
+			continue
+		}
 		descrip := strings.ReplaceAll(strings.ReplaceAll(info.Description, " ", "_"), "/", "")
 		reqs = append(reqs, &monitoringpb.CreateMetricDescriptorRequest{
 			Name: "projects/" + projectID,
@@ -443,7 +453,7 @@ func getMetricDescriptorNames(t *target.Target, projectID string) (reqs []*monit
 		})
 	}
 	for iface := range t.Ifaces {
-		descrip := strings.ReplaceAll(iface, " ", "_")
+		descrip := info.IfaceName(iface)
 		reqs = append(reqs, &monitoringpb.CreateMetricDescriptorRequest{
 			Name: "projects/" + projectID,
 			MetricDescriptor: &metricpb.MetricDescriptor{
@@ -469,58 +479,11 @@ func getMetricDescriptorNames(t *target.Target, projectID string) (reqs []*monit
 			},
 		})
 	}
-	if t.Wireless != nil {
-		reqs = append(reqs, &monitoringpb.CreateMetricDescriptorRequest{
-			Name: "projects/" + projectID,
-			MetricDescriptor: &metricpb.MetricDescriptor{
-				Name:        fmt.Sprintf("%s-wireless-clientcount", t.Name),
-				Type:        fmt.Sprintf("%s/wireless/clientcount", prefix),
-				MetricKind:  metricpb.MetricDescriptor_GAUGE,
-				ValueType:   metricpb.MetricDescriptor_INT64,
-				Unit:        "By{received}/s",
-				Description: fmt.Sprintf("%s wireless client count", t.Name),
-				DisplayName: fmt.Sprintf("%s wireless client count", t.Name),
-			},
-		})
-		reqs = append(reqs, &monitoringpb.CreateMetricDescriptorRequest{
-			Name: "projects/" + projectID,
-			MetricDescriptor: &metricpb.MetricDescriptor{
-				Name:        fmt.Sprintf("%s-wireless-ccq", t.Name),
-				Type:        fmt.Sprintf("%s/wireless/ccq", prefix),
-				MetricKind:  metricpb.MetricDescriptor_GAUGE,
-				ValueType:   metricpb.MetricDescriptor_INT64,
-				Unit:        "By{received}/s",
-				Description: fmt.Sprintf("%s wireless overall CCQ", t.Name),
-				DisplayName: fmt.Sprintf("%s wireless overall CCQ", t.Name),
-			},
-		})
-		for _, wcl := range t.Wireless.ClientConnections {
-			mac := strings.ReplaceAll(wcl.MAC, ":", "")
-			reqs = append(reqs, &monitoringpb.CreateMetricDescriptorRequest{
-				Name: "projects/" + projectID,
-				MetricDescriptor: &metricpb.MetricDescriptor{
-					Name:        fmt.Sprintf("%s-wireless-client-%s(%s)-signalstrength", t.Name, wcl.Name, mac),
-					Type:        fmt.Sprintf("%s/wireless/clients/%s/%s/signalstrength", prefix, wcl.Name, mac),
-					MetricKind:  metricpb.MetricDescriptor_GAUGE,
-					ValueType:   metricpb.MetricDescriptor_INT64,
-					Unit:        "By{received}/s",
-					Description: fmt.Sprintf("%s wireless client %s(%s) signal strength", t.Name, wcl.Name, mac),
-					DisplayName: fmt.Sprintf("%s wireless client %s(%s) signal strength", t.Name, wcl.Name, mac),
-				},
-			})
-			reqs = append(reqs, &monitoringpb.CreateMetricDescriptorRequest{
-				Name: "projects/" + projectID,
-				MetricDescriptor: &metricpb.MetricDescriptor{
-					Name:        fmt.Sprintf("%s-wireless-client-%s(%s)-snr", t.Name, wcl.Name, mac),
-					Type:        fmt.Sprintf("%s/wireless/clients/%s/%s/snr", prefix, wcl.Name, mac),
-					MetricKind:  metricpb.MetricDescriptor_GAUGE,
-					ValueType:   metricpb.MetricDescriptor_INT64,
-					Unit:        "By{received}/s",
-					Description: fmt.Sprintf("%s wireless client %s(%s) signal to noise ratio", t.Name, wcl.Name, mac),
-					DisplayName: fmt.Sprintf("%s wireless client %s(%s) SNR", t.Name, wcl.Name, mac),
-				},
-			})
-		}
-	}
+	reqs = append(reqs, systemDescriptors(t, prefix, projectID)...)
+	reqs = append(reqs, pollDescriptors(t, prefix, projectID)...)
+	reqs = append(reqs, cpuDescriptors(t, prefix, projectID)...)
+	reqs = append(reqs, memoryDescriptors(t, prefix, projectID)...)
+	reqs = append(reqs, protocolDescriptors(t, prefix, projectID)...)
+	reqs = append(reqs, extensionDescriptors(t, prefix, projectID)...)
 	return reqs
 }
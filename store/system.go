@@ -0,0 +1,72 @@
+package store
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/jcmturner/snmpgcpmonitoring/target"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
+)
+
+// maxLabelValueLen is the longest label value accepted by Cloud Monitoring
+const maxLabelValueLen = 1024
+
+// systemLabels are the identity labels attached to the system metrics
+var systemLabels = []string{"sys_name", "sys_descr", "sys_object_id", "sys_location", "sys_contact"}
+
+func systemTimeSeries(t *target.Target, prefix string, now *timestamp.Timestamp) (series []*monitoringpb.TimeSeries) {
+	if t.System == nil || t.System.Timestamp.IsZero() {
+		return
+	}
+	labels := map[string]string{
+		"sys_name":      labelValue(t.System.Name),
+		"sys_descr":     labelValue(t.System.Descr),
+		"sys_object_id": labelValue(t.System.ObjectID),
+		"sys_location":  labelValue(t.System.Location),
+		"sys_contact":   labelValue(t.System.Contact),
+	}
+	return append(series,
+		timeSeries(fmt.Sprintf("%s/system/uptime", prefix), labels, now, int64Value(int64(t.System.UpTime.Seconds()))),
+		timeSeries(fmt.Sprintf("%s/system/boottime", prefix), labels, now, int64Value(t.System.BootTime.Unix())),
+		timeSeries(fmt.Sprintf("%s/system/reboots", prefix), labels, now, int64Value(t.System.Reboots)),
+	)
+}
+
+func systemDescriptors(t *target.Target, prefix, projectID string) (reqs []*monitoringpb.CreateMetricDescriptorRequest) {
+	if t.System == nil || t.System.Timestamp.IsZero() {
+		return
+	}
+	return append(reqs,
+		gaugeDescriptor(projectID,
+			fmt.Sprintf("%s-system-uptime", t.Name),
+			fmt.Sprintf("%s/system/uptime", prefix),
+			metricpb.MetricDescriptor_INT64, "s",
+			fmt.Sprintf("%s uptime", t.Name), systemLabels...),
+		gaugeDescriptor(projectID,
+			fmt.Sprintf("%s-system-boottime", t.Name),
+			fmt.Sprintf("%s/system/boottime", prefix),
+			metricpb.MetricDescriptor_INT64, "s",
+			fmt.Sprintf("%s boot time in seconds since the epoch", t.Name), systemLabels...),
+		// the reboot count is held in memory so restarts from zero whenever the poller restarts, the boot time
+		// is the reliable way to see reboots across deployments
+		gaugeDescriptor(projectID,
+			fmt.Sprintf("%s-system-reboots", t.Name),
+			fmt.Sprintf("%s/system/reboots", prefix),
+			metricpb.MetricDescriptor_INT64, "1",
+			fmt.Sprintf("%s reboots seen since the poller started", t.Name), systemLabels...),
+	)
+}
+
+// labelValue truncates the value to the maximum length of a label value
+func labelValue(v string) string {
+	if len(v) <= maxLabelValueLen {
+		return v
+	}
+	i := maxLabelValueLen
+	for i > 0 && !utf8.RuneStart(v[i]) {
+		i--
+	}
+	return v[:i]
+}
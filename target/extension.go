@@ -0,0 +1,70 @@
+package target
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+)
+
+// Extension is a vendor specific collector configured for a target
+type Extension interface {
+	// Collect polls the target for the extension's values
+	Collect(t *Target, verbose bool) error
+	// Metrics returns the current values of the extension to be published for the target
+	Metrics(t *Target) []*info.Metric
+}
+
+// ExtensionDecoder creates an extension from its JSON configuration in a target's Extensions
+type ExtensionDecoder func(data json.RawMessage) (Extension, error)
+
+var extensions = make(map[string]ExtensionDecoder)
+
+// NoOptions makes an ExtensionDecoder for an extension that has no configuration options.
+// The configuration given for the extension must still be valid JSON.
+func NoOptions(newExt func() Extension) ExtensionDecoder {
+	return func(data json.RawMessage) (Extension, error) {
+		var cfg struct{}
+		err := json.Unmarshal(data, &cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newExt(), nil
+	}
+}
+
+// RegisterExtension makes an extension available to be configured on targets under the name given.
+// It is intended to be called from the init function of the package implementing the extension.
+func RegisterExtension(name string, d ExtensionDecoder) {
+	if _, dup := extensions[name]; dup {
+		panic(fmt.Sprintf("extension %s registered twice", name))
+	}
+	extensions[name] = d
+}
+
+// ExtensionNames returns the names of the extensions configured for the target in a consistent order
+func (t *Target) ExtensionNames() []string {
+	var names []string
+	for name := range t.Exts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (t *Target) decodeExtensions() error {
+	t.Exts = make(map[string]Extension)
+	for name, data := range t.Extensions {
+		d, ok := extensions[name]
+		if !ok {
+			return fmt.Errorf("unknown extension %s configured for %s", name, t.Name)
+		}
+		ext, err := d(data)
+		if err != nil {
+			return fmt.Errorf("invalid %s extension configuration for %s: %v", name, t.Name, err)
+		}
+		t.Exts[name] = ext
+	}
+	return nil
+}
@@ -3,8 +3,10 @@ package target
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"regexp"
 	"time"
 
 	"github.com/jcmturner/snmpgcpmonitoring/info"
@@ -33,12 +35,21 @@ type Target struct {
 	unmarshalTarget
 
 	Client      *gosnmp.GoSNMP           `json:"-"`
+	System      *info.System             `json:"-"`
 	Ifaces      map[string]*info.Iface   `json:"-"`
 	IfaceIndex  map[string]string        `json:"-"` // OIDTail : Descr
 	CPU         map[string]int64         `json:"-"` // percentage usage of each cpu
+	CPUAverage  float64                  `json:"-"` // average percentage usage across all cpus
+	CPURaw      *info.CPURaw             `json:"-"`
+	Load        *info.Load               `json:"-"`
 	Storage     map[string]*info.Storage `json:"-"`
 	StrgIndex   map[string]string        `json:"-"` // OIDTail : Descr
-	Wireless    *info.Wireless           `json:"-"`
+	StrgExclude []*regexp.Regexp         `json:"-"` // compiled StorageExcludePatterns
+	Memory      *info.Memory             `json:"-"`
+	Protocols   *info.Protocols          `json:"-"`
+	Polls       map[string]*info.Poll    `json:"-"` // collector name : Poll
+	ActivePoll  *info.Poll               `json:"-"` // the poll of the collector currently running
+	Exts        map[string]Extension     `json:"-"` // extension name : Extension
 	Duration    time.Duration            `json:"-"`
 	CollectTime time.Time                `json:"-"`
 }
@@ -49,20 +60,14 @@ type unmarshalTarget struct {
 	Community     string
 	Interfaces    []string // The ifDesr of the interfaces of interest
 	StorageFilter []string
-	Frequency     string
-	Extensions    *Extensions `json:"Extensions,omitempty"`
-}
-
-type Extensions struct {
-	Mikrotik *Mikrotik `json:"Mikrotik,omitempty"`
-}
-
-type Mikrotik struct {
-	WirelessInterface string
-	WirelessClients   []struct {
-		Name string `json:"Name"`
-		MAC  string `json:"MAC"`
-	} `json:"WirelessClientMACs"`
+	// StorageExcludeTypes are the hrStorageType names to ignore (Other, RAM, VirtualMemory, FixedDisk,
+	// RemovableDisk, FloppyDisk, CompactDisc, RamDisk, FlashMemory, NetworkDisk)
+	StorageExcludeTypes []string
+	// StorageExcludePatterns are regular expressions matched against the hrStorageDescr of storage to ignore
+	StorageExcludePatterns []string
+	Frequency              string
+	// Extensions are the configuration of each vendor extension to use keyed by the name of the extension
+	Extensions map[string]json.RawMessage `json:"Extensions,omitempty"`
 }
 
 func (t *Target) UnmarshalJSON(data []byte) error {
@@ -76,9 +81,19 @@ func (t *Target) UnmarshalJSON(data []byte) error {
 	t.Community = u.Community
 	t.Interfaces = u.Interfaces
 	t.StorageFilter = u.StorageFilter
+	t.StorageExcludeTypes = u.StorageExcludeTypes
+	t.StorageExcludePatterns = u.StorageExcludePatterns
+	for _, p := range t.StorageExcludePatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid storage exclude pattern %s for %s: %v", p, t.Name, err)
+		}
+		t.StrgExclude = append(t.StrgExclude, re)
+	}
 	t.Extensions = u.Extensions
-	if t.Extensions != nil && t.Extensions.Mikrotik != nil {
-		t.Wireless = info.NewWireless()
+	err = t.decodeExtensions()
+	if err != nil {
+		return err
 	}
 	t.init()
 	t.Frequency = u.Frequency
@@ -87,11 +102,17 @@ func (t *Target) UnmarshalJSON(data []byte) error {
 }
 
 func (t *Target) init() {
+	t.System = new(info.System)
 	t.Ifaces = make(map[string]*info.Iface)
 	t.IfaceIndex = make(map[string]string)
 	t.CPU = make(map[string]int64)
+	t.CPURaw = info.NewCPURaw()
+	t.Load = new(info.Load)
 	t.Storage = make(map[string]*info.Storage)
 	t.StrgIndex = make(map[string]string)
+	t.Memory = info.NewMemory()
+	t.Protocols = info.NewProtocols()
+	t.Polls = make(map[string]*info.Poll)
 	for _, iface := range t.Interfaces {
 		t.Ifaces[iface] = nil
 	}
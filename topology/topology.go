@@ -0,0 +1,150 @@
+// Package topology merges the LLDP neighbours of the targets into a single view of what is connected where.
+package topology
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+)
+
+// Topology is the devices and the links between them seen by LLDP
+type Topology struct {
+	Nodes []*Node
+	Links []*Link
+}
+
+// Node is a device, either a target or a neighbour of a target
+type Node struct {
+	Name                string
+	Target              bool // the device is a monitored target rather than only seen as a neighbour
+	ManagementAddresses []string
+}
+
+// Link is a connection between ports of two devices.
+// A link seen from both ends appears only once.
+type Link struct {
+	From     string
+	FromPort string
+	To       string
+	ToPort   string
+}
+
+// Device is a monitored target and the neighbours collected from it.
+// Name should be the target's sysName, where it has been collected, so it can be matched to the system names
+// its neighbours report. Neighbours is nil if they could not be collected.
+type Device struct {
+	Name       string
+	Neighbours *info.LLDP
+}
+
+// New merges the LLDP neighbours of the devices
+func New(devices []Device) *Topology {
+	nodes := make(map[string]*Node)
+	node := func(name string) *Node {
+		n, ok := nodes[name]
+		if !ok {
+			n = &Node{Name: name}
+			nodes[name] = n
+		}
+		return n
+	}
+	var links []*Link
+	// devices reporting the links from their side, and the pairs of devices they have reported links between
+	reporters := make(map[string]bool)
+	reported := make(map[string]bool)
+	for _, d := range devices {
+		name := d.Name
+		node(name).Target = true
+		if d.Neighbours == nil || d.Neighbours.Timestamp.IsZero() {
+			continue
+		}
+		reporters[name] = true
+		for _, nbr := range d.Neighbours.Neighbours {
+			remote := nbr.SystemName
+			if remote == "" {
+				remote = nbr.ChassisID
+			}
+			n := node(remote)
+			n.ManagementAddresses = mergeAddresses(n.ManagementAddresses, nbr.ManagementAddresses)
+			remotePort := nbr.PortDescription
+			if remotePort == "" {
+				remotePort = nbr.PortID
+			}
+			links = append(links, &Link{From: name, FromPort: nbr.LocalPort, To: remote, ToPort: remotePort})
+			reported[name+"\x00"+remote] = true
+		}
+	}
+	top := new(Topology)
+	for _, n := range nodes {
+		top.Nodes = append(top.Nodes, n)
+	}
+	sort.Slice(top.Nodes, func(i, j int) bool { return top.Nodes[i].Name < top.Nodes[j].Name })
+	// where both ends of a link report it the remote port names may not match the local port names of the other
+	// end, so only the links reported by the first of the two devices by name are kept
+	for _, l := range links {
+		if reporters[l.To] && l.To < l.From && reported[l.To+"\x00"+l.From] {
+			continue
+		}
+		top.Links = append(top.Links, l)
+	}
+	sort.Slice(top.Links, func(i, j int) bool {
+		a, b := top.Links[i], top.Links[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.FromPort != b.FromPort {
+			return a.FromPort < b.FromPort
+		}
+		return a.To < b.To
+	})
+	return top
+}
+
+func mergeAddresses(a, b []string) []string {
+	seen := make(map[string]bool)
+	for _, s := range a {
+		seen[s] = true
+	}
+	for _, s := range b {
+		if !seen[s] {
+			seen[s] = true
+			a = append(a, s)
+		}
+	}
+	sort.Strings(a)
+	return a
+}
+
+// JSON writes the topology as indented JSON
+func (top *Topology) JSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(top)
+}
+
+// DOT writes the topology as a Graphviz DOT undirected graph with the ports as edge labels
+func (top *Topology) DOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("graph topology {\n")
+	for _, n := range top.Nodes {
+		shape := "ellipse"
+		if n.Target {
+			shape = "box"
+		}
+		label := n.Name
+		if len(n.ManagementAddresses) > 0 {
+			label += "\n" + strings.Join(n.ManagementAddresses, "\n")
+		}
+		fmt.Fprintf(&b, "\t%q [shape=%s, label=%q];\n", n.Name, shape, label)
+	}
+	for _, l := range top.Links {
+		fmt.Fprintf(&b, "\t%q -- %q [taillabel=%q, headlabel=%q];\n", l.From, l.To, l.FromPort, l.ToPort)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,109 @@
+package topology
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jcmturner/snmpgcpmonitoring/info"
+)
+
+func neighbours(nbrs ...*info.Neighbour) *info.LLDP {
+	return &info.LLDP{Neighbours: nbrs, Timestamp: time.Now()}
+}
+
+func TestNewLinks(t *testing.T) {
+	var tests = []struct {
+		name    string
+		devices []Device
+		want    []Link
+	}{
+		{
+			name: "one end reports",
+			devices: []Device{
+				{Name: "sw1", Neighbours: neighbours(&info.Neighbour{LocalPort: "ge-0/0/1", SystemName: "rtr1", PortID: "ether2"})},
+				{Name: "rtr1"},
+			},
+			want: []Link{{From: "sw1", FromPort: "ge-0/0/1", To: "rtr1", ToPort: "ether2"}},
+		},
+		{
+			name: "both ends report with different port names",
+			devices: []Device{
+				{Name: "sw1", Neighbours: neighbours(&info.Neighbour{LocalPort: "ge-0/0/1", SystemName: "rtr1", PortID: "ether2"})},
+				{Name: "rtr1", Neighbours: neighbours(&info.Neighbour{LocalPort: "ether2", SystemName: "sw1", PortID: "515", PortDescription: "uplink"})},
+			},
+			want: []Link{{From: "rtr1", FromPort: "ether2", To: "sw1", ToPort: "uplink"}},
+		},
+		{
+			name: "other end reports nothing back",
+			devices: []Device{
+				{Name: "a", Neighbours: neighbours()},
+				{Name: "b", Neighbours: neighbours(&info.Neighbour{LocalPort: "p1", SystemName: "a", PortID: "p2"})},
+			},
+			want: []Link{{From: "b", FromPort: "p1", To: "a", ToPort: "p2"}},
+		},
+		{
+			name: "other end not collected",
+			devices: []Device{
+				{Name: "a"},
+				{Name: "b", Neighbours: neighbours(&info.Neighbour{LocalPort: "p1", SystemName: "a", PortID: "p2"})},
+			},
+			want: []Link{{From: "b", FromPort: "p1", To: "a", ToPort: "p2"}},
+		},
+		{
+			name: "neighbour without a system name",
+			devices: []Device{
+				{Name: "sw1", Neighbours: neighbours(&info.Neighbour{LocalPort: "p1", ChassisID: "00:11:22:33:44:55", PortID: "eth0"})},
+			},
+			want: []Link{{From: "sw1", FromPort: "p1", To: "00:11:22:33:44:55", ToPort: "eth0"}},
+		},
+		{
+			name: "parallel links are kept",
+			devices: []Device{
+				{Name: "a", Neighbours: neighbours(
+					&info.Neighbour{LocalPort: "p1", SystemName: "b", PortID: "q1"},
+					&info.Neighbour{LocalPort: "p2", SystemName: "b", PortID: "q2"},
+				)},
+				{Name: "b", Neighbours: neighbours(
+					&info.Neighbour{LocalPort: "q1", SystemName: "a", PortID: "p1"},
+					&info.Neighbour{LocalPort: "q2", SystemName: "a", PortID: "p2"},
+				)},
+			},
+			want: []Link{
+				{From: "a", FromPort: "p1", To: "b", ToPort: "q1"},
+				{From: "a", FromPort: "p2", To: "b", ToPort: "q2"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []Link
+			for _, l := range New(test.devices).Links {
+				got = append(got, *l)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("links = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewNodes(t *testing.T) {
+	top := New([]Device{
+		{Name: "sw1", Neighbours: neighbours(
+			&info.Neighbour{LocalPort: "p1", SystemName: "ap1", ManagementAddresses: []string{"10.0.0.2"}},
+			&info.Neighbour{LocalPort: "p2", SystemName: "ap1", ManagementAddresses: []string{"10.0.0.1", "10.0.0.2"}},
+		)},
+	})
+	want := []Node{
+		{Name: "ap1", ManagementAddresses: []string{"10.0.0.1", "10.0.0.2"}},
+		{Name: "sw1", Target: true},
+	}
+	var got []Node
+	for _, n := range top.Nodes {
+		got = append(got, *n)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nodes = %+v, want %+v", got, want)
+	}
+}